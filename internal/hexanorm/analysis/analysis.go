@@ -1,13 +1,15 @@
 package analysis
 
 import (
-	"encoding/json"
 	"fmt"
+	"io/fs"
+	"log"
 	"path/filepath"
 	"regexp"
 	"strings"
 
 	curex "github.com/cucumber/cucumber-expressions-go"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/config"
 	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
 	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/graph"
 	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/parser"
@@ -16,47 +18,132 @@ import (
 // Analyzer performs static analysis on files to populate the semantic graph.
 // It handles import resolution, layer detection, and BDD step matching.
 type Analyzer struct {
-	Graph *graph.Graph
-	// Cache TSConfig for resolution
-	tsConfigs map[string]TSConfig
-	goMods    map[string]GoMod
-}
-
-// TSConfig represents a subset of tsconfig.json used for import resolution.
-type TSConfig struct {
-	BaseUrl string              `json:"baseUrl"`
-	Paths   map[string][]string `json:"paths"`
-}
-
-// GoMod represents basic module information from go.mod.
-type GoMod struct {
-	Module string
+	Graph  *graph.Graph
+	Config *config.Config
+	// Root of the analyzed repository: violation fingerprints use paths relative to it
+	RootDir string
+	// Accepted violations, nil if there is no baseline
+	Baseline *Baseline
+	// Compiled layer overrides and patterns, in matching order
+	layerMatchers []layerMatcher
+	// Compiled bounded-context definitions and global public API patterns
+	contextMatchers  []contextMatcher
+	contextPublicAPI []*regexp.Regexp
+	// Compiled path patterns of files declaring ports, and of files declaring adapters
+	portPatterns    []*regexp.Regexp
+	adapterPatterns []*regexp.Regexp
+	// Go types of the adapter layers that are adapters only while they implement a port, keyed by file path
+	adapterCandidates map[string][]*domain.Node
+	// Directories named `features`, and whether they hold BDD specifications, walked at most once each
+	featuresDirs map[string]bool
+	// Compiled pattern of the Gherkin tags referencing requirements, and of the requirement directories
+	requirementTag  *regexp.Regexp
+	requirementDirs []*regexp.Regexp
+	// Cache manifests for resolution
+	tsConfigs        map[string]TSConfig // keyed by file path
+	packages         map[string]PackageJSON
+	goMods           map[string]GoMod
+	goWorks          map[string]GoWork
+	jvmProjects      map[string]JVMProject
+	pythonProjects   map[string]PythonProject
+	crates           map[string]Crate
+	composerProjects map[string]ComposerProject
+	// Analyzed Java and Kotlin files keyed by package directory, and the imports of each file that could only
+	// be resolved to a guessed path, keyed by import, until the class they name is analyzed
+	jvmPackages map[string][]string
+	jvmPending  map[string]map[string]string
 }
 
 // NewAnalyzer creates a new Analyzer instance associated with the given graph.
-func NewAnalyzer(g *graph.Graph) *Analyzer {
+// If cfg is nil, the default configuration is used.
+func NewAnalyzer(g *graph.Graph, cfg *config.Config) *Analyzer {
+	if cfg == nil {
+		defaults := config.DefaultConfig
+		cfg = &defaults
+	}
+	contexts, publicAPI := compileContextMatchers(cfg)
+	// LoadConfig validates the pattern, an empty or invalid one disables requirement tags
+	var requirementTag *regexp.Regexp
+	if cfg.Requirements.TagPattern != "" {
+		requirementTag, _ = regexp.Compile(cfg.Requirements.TagPattern)
+	}
 	return &Analyzer{
-		Graph:     g,
-		tsConfigs: make(map[string]TSConfig),
-		goMods:    make(map[string]GoMod),
+		Graph:             g,
+		Config:            cfg,
+		layerMatchers:     compileLayerMatchers(cfg),
+		contextMatchers:   contexts,
+		contextPublicAPI:  publicAPI,
+		portPatterns:      compilePatterns(cfg.Ports.PortPatterns),
+		adapterPatterns:   compilePatterns(cfg.Ports.AdapterPatterns),
+		adapterCandidates: make(map[string][]*domain.Node),
+		featuresDirs:      make(map[string]bool),
+		requirementTag:    requirementTag,
+		requirementDirs:   compilePatterns(dirPatterns(cfg.Requirements.Dirs)),
+		tsConfigs:         make(map[string]TSConfig),
+		packages:          make(map[string]PackageJSON),
+		goMods:            make(map[string]GoMod),
+		goWorks:           make(map[string]GoWork),
+		jvmProjects:       make(map[string]JVMProject),
+		pythonProjects:    make(map[string]PythonProject),
+		crates:            make(map[string]Crate),
+		composerProjects:  make(map[string]ComposerProject),
+		jvmPackages:       make(map[string][]string),
+		jvmPending:        make(map[string]map[string]string),
 	}
 }
 
+// IsManifest reports whether the file configures import resolution: tsconfig.json, package.json, go.mod,
+// go.work, pom.xml, build.gradle, pyproject.toml, setup.cfg, Cargo.toml or composer.json.
+// Manifests should be analyzed before the code files importing through them.
+func IsManifest(path string) bool {
+	base := filepath.Base(path)
+	return isTSConfig(path) || base == "package.json" || base == "go.mod" || base == "go.work" || base == "Cargo.toml" || base == "composer.json" ||
+		isJVMBuildFile(path) || isPythonProjectFile(path)
+}
+
 // AnalyzeFile scans a single file and updates the graph with its node and relationships.
-// It handles configuration files (tsconfig.json, go.mod), Gherkin feature files, and source code.
+// It handles configuration files (tsconfig.json, package.json, go.mod, go.work, pom.xml, build.gradle, pyproject.toml,
+// Cargo.toml, composer.json), requirement files, Gherkin feature files, and source code.
 func (a *Analyzer) AnalyzeFile(path string, content []byte) error {
 	// Pre-scan for config files
-	if filepath.Base(path) == "tsconfig.json" {
+	if isTSConfig(path) {
 		a.parseTSConfig(path, content)
 		return nil
 	}
+	if filepath.Base(path) == "package.json" {
+		a.parsePackageJSON(path, content)
+		return nil
+	}
 	if filepath.Base(path) == "go.mod" {
 		a.parseGoMod(path, content)
 		return nil
 	}
+	if filepath.Base(path) == "go.work" {
+		a.parseGoWork(path, content)
+		return nil
+	}
+	if isJVMBuildFile(path) {
+		a.parseJVMBuild(path, content)
+		return nil
+	}
+	if isPythonProjectFile(path) {
+		a.parsePythonProject(path, content)
+		return nil
+	}
+	if filepath.Base(path) == "Cargo.toml" {
+		a.parseCargo(path, content)
+		return nil
+	}
+	if filepath.Base(path) == "composer.json" {
+		a.parseComposer(path, content)
+		return nil
+	}
+	if a.isRequirementFile(path) {
+		return a.analyzeRequirement(path, content)
+	}
 
 	// 1. Determine Layer/Type
-	layer := detectLayer(path)
+	layer, layerSource := a.detectLayer(path)
 
 	// 2. Create/Update Node
 	nodeID := path
@@ -75,8 +162,10 @@ func (a *Analyzer) AnalyzeFile(path string, content []byte) error {
 				ID:   nodeID,
 				Kind: domain.NodeKindCode,
 				Metadata: map[string]interface{}{
-					"layer":    layer,
-					"language": "unknown",
+					"layer":        layer,
+					"layer_source": layerSource,
+					"context":      a.detectContext(path),
+					"language":     "unknown",
 				},
 			}
 			a.Graph.AddNode(node)
@@ -84,27 +173,71 @@ func (a *Analyzer) AnalyzeFile(path string, content []byte) error {
 		return nil
 	}
 
+	// Source annotations may override the layer
+	annotations, _ := parser.ParseAnnotations(content, lang)
+	if l := annotatedLayer(annotations); l != "" {
+		layer, layerSource = a.includedLayer(l), "annotation"
+	}
+
+	context := a.detectContext(path)
+	previousTests := a.testsOf(nodeID)
+	previousRequirements := a.requirementsAnnotatedIn(nodeID)
+	previousPortsAndAdapters := a.portsAndAdaptersOf(nodeID)
 	node = &domain.Node{
 		ID:   nodeID,
 		Kind: domain.NodeKindCode,
 		Metadata: map[string]interface{}{
-			"layer":    layer,
-			"language": string(lang),
+			"layer":        layer,
+			"layer_source": layerSource,
+			"context":      context,
+			"language":     string(lang),
 		},
 	}
 	a.Graph.AddNode(node)
+	if lang == parser.LangGo {
+		a.analyzeGoPackage(node)
+	}
+	newJVMFile := false
+	if lang == parser.LangJava || lang == parser.LangKotlin {
+		newJVMFile = a.indexJVMFile(path)
+	}
 
 	// 3. Parse Imports
 	imports, err := parser.ParseImports(content, lang)
 	if err == nil {
+		// Record where each target is first imported, to locate violations
+		positions := make(map[string]interface{})
 		for _, imp := range imports {
-			targetID := a.resolveImport(path, imp, lang)
+			targetID := a.resolveImport(path, imp.Path, lang)
+			if isExternalImport(imp.Path, targetID) {
+				a.ensureExternal(targetID, lang)
+			}
 			a.Graph.AddEdge(nodeID, targetID, domain.EdgeTypeImports)
+			if _, ok := positions[targetID]; !ok {
+				positions[targetID] = propertiesOf(sourceRange{imp.Line, imp.Column, imp.EndLine, imp.EndColumn})
+			}
 		}
+		node = withProperties(node, map[string]interface{}{"import_positions": positions})
+		a.Graph.AddNode(node)
 	}
+	if newJVMFile {
+		a.resolvePendingJVMImports()
+	}
+
+	// 4. Extract Symbols
+	a.analyzeSymbols(path, content, lang, layer, context)
 
-	// 4. Parse Step Definitions (if Test layer)
-	if layer == "interface" || strings.Contains(path, "test") || strings.Contains(path, "steps") {
+	// 5. Recognize Tests
+	node = a.analyzeTests(node, content, lang, previousTests)
+
+	// 6. Apply Source Annotations (after symbols, which they may target)
+	node = a.analyzeAnnotations(node, annotations, previousRequirements)
+
+	// 7. Recognize Ports and Adapters
+	node = a.analyzePortsAndAdapters(node, content, lang, previousPortsAndAdapters)
+
+	// 8. Parse Step Definitions (if Test layer, or within a features directory, like Behat's features/bootstrap)
+	if layer == "interface" || strings.Contains(path, "test") || strings.Contains(path, "steps") || a.inFeaturesDir(path) {
 		steps, err := parser.ParseStepDefinitions(content, lang)
 		if err == nil && len(steps) > 0 {
 			for _, s := range steps {
@@ -118,6 +251,7 @@ func (a *Analyzer) AnalyzeFile(path string, content []byte) error {
 						"function_name": s.FunctionName,
 						"filepath":      path,
 						"line":          s.Line,
+						"end_line":      s.EndLine,
 					},
 				}
 				a.Graph.AddNode(stepNode)
@@ -126,6 +260,9 @@ func (a *Analyzer) AnalyzeFile(path string, content []byte) error {
 		}
 	}
 
+	// 9. Record Calls (resolved by IndexCallGraph)
+	a.analyzeCalls(path, content, lang)
+
 	return nil
 }
 
@@ -135,81 +272,106 @@ func (a *Analyzer) analyzeGherkin(path string, content []byte) error {
 	if err != nil {
 		return err
 	}
+	for _, w := range feat.Warnings {
+		log.Printf("Warning: %s: %s", path, w)
+	}
+
+	for _, dir := range featuresDirsOf(path) {
+		a.featuresDirs[dir] = true
+	}
 
 	featID := "gh:feat:" + strings.ReplaceAll(feat.Name, " ", "_")
 	featNode := &domain.Node{
 		ID:   featID,
 		Kind: domain.NodeKindGherkinFeature,
 		Properties: map[string]interface{}{
-			"name": feat.Name,
-			"file": path,
+			"name":     feat.Name,
+			"file":     path,
+			"line":     feat.Line,
+			"tags":     feat.Tags,
+			"language": feat.Language,
+			"warnings": feat.Warnings,
 		},
 	}
 	a.Graph.AddNode(featNode)
+	a.linkFeatureRequirements(featID, feat.Tags)
 
 	for _, sc := range feat.Scenarios {
 		scID := "gh:scen:" + strings.ReplaceAll(sc.Name, " ", "_")
+		if sc.Example > 0 {
+			// Outline rows may share the outline's name
+			scID = fmt.Sprintf("%s#%d", scID, sc.Example)
+		}
+		texts := make([]string, len(sc.StepDetails))
+		for i, step := range sc.StepDetails {
+			texts[i] = step.Text
+		}
 		scNode := &domain.Node{
 			ID:   scID,
 			Kind: domain.NodeKindGherkinScenario,
 			Properties: map[string]interface{}{
-				"name":       sc.Name,
-				"file":       path,
-				"steps_hash": sc.StepsHash,
-				"line":       sc.Line,
-				"steps":      sc.Steps,
+				"name":         sc.Name,
+				"file":         path,
+				"steps_hash":   sc.StepsHash,
+				"line":         sc.Line,
+				"steps":        sc.Steps,
+				"step_texts":   texts,
+				"step_details": sc.StepDetails,
+				"tags":         sc.Tags,
+				"rule":         sc.Rule,
 			},
 		}
 		a.Graph.AddNode(scNode)
+		a.linkScenarioRequirements(scID, sc.Tags)
 	}
 	return nil
 }
 
-// detectLayer infers the architectural layer based on the file path.
-// It returns "domain", "application", "infrastructure", "interface", or empty string.
-func detectLayer(path string) string {
-	if strings.Contains(path, "/domain/") {
-		return "domain"
-	}
-	if strings.Contains(path, "/application/") {
-		return "application"
-	}
-	if strings.Contains(path, "/infrastructure/") {
-		return "infrastructure"
-	}
-	if strings.Contains(path, "/interface/") || strings.Contains(path, "/api/") {
-		return "interface"
+// inFeaturesDir reports whether the path is within a `features` directory holding BDD specifications:
+// `.feature` files, analyzed or on disk, or a `step_definitions` or `steps` sub-directory.
+// Other directories named after product features, e.g. src/features/cart, hold no step definitions.
+func (a *Analyzer) inFeaturesDir(path string) bool {
+	for _, dir := range featuresDirsOf(path) {
+		if a.isFeaturesDir(dir) {
+			return true
+		}
 	}
-	return ""
+	return false
 }
 
-// Config Parsing Helpers
-
-func (a *Analyzer) parseTSConfig(path string, content []byte) {
-	// Simplified parsing for compilerOptions.paths and baseUrl
-	var raw struct {
-		CompilerOptions struct {
-			BaseUrl string              `json:"baseUrl"`
-			Paths   map[string][]string `json:"paths"`
-		} `json:"compilerOptions"`
-	}
-	if err := json.Unmarshal(content, &raw); err == nil {
-		dir := filepath.Dir(path)
-		a.tsConfigs[dir] = TSConfig{
-			BaseUrl: raw.CompilerOptions.BaseUrl,
-			Paths:   raw.CompilerOptions.Paths,
+// featuresDirsOf returns the directories named `features` enclosing the path, outermost first.
+func featuresDirsOf(path string) []string {
+	var dirs []string
+	segments := strings.Split(filepath.Dir(path), string(filepath.Separator))
+	for i, segment := range segments {
+		if segment == "features" {
+			dirs = append(dirs, strings.Join(segments[:i+1], string(filepath.Separator)))
 		}
 	}
+	return dirs
 }
 
-func (a *Analyzer) parseGoMod(path string, content []byte) {
-	// Simple regex to find module name
-	re := regexp.MustCompile(`module\s+([^\s]+)`)
-	matches := re.FindSubmatch(content)
-	if len(matches) > 1 {
-		dir := filepath.Dir(path)
-		a.goMods[dir] = GoMod{Module: string(matches[1])}
+// isFeaturesDir reports whether the directory, or one of its sub-directories, holds Gherkin features or step definitions.
+// The directory is walked once, analyzed features mark their enclosing directories as they come.
+func (a *Analyzer) isFeaturesDir(dir string) bool {
+	if found, ok := a.featuresDirs[dir]; ok {
+		return found
 	}
+
+	found := false
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && path != dir && (d.Name() == "step_definitions" || d.Name() == "steps") ||
+			!d.IsDir() && filepath.Ext(path) == ".feature" {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	a.featuresDirs[dir] = found
+	return found
 }
 
 // Import Resolution
@@ -218,24 +380,18 @@ func (a *Analyzer) resolveImport(sourcePath, importStr string, lang parser.Langu
 	importStr = strings.Trim(importStr, "\"'`")
 
 	switch lang {
-	case parser.LangTypeScript:
+	case parser.LangTypeScript, parser.LangTSX, parser.LangJavaScript:
 		return a.resolveTSImport(sourcePath, importStr)
 	case parser.LangGo:
 		return a.resolveGoImport(sourcePath, importStr)
+	case parser.LangJava, parser.LangKotlin:
+		return a.resolveJVMImport(sourcePath, importStr)
 	case parser.LangPython:
-		// Relative imports
-		if strings.HasPrefix(importStr, ".") {
-			return filepath.Join(filepath.Dir(sourcePath), importStr)
-		}
-		// Absolute/Package? Return as is for now.
-		return importStr
+		return a.resolvePythonImport(sourcePath, importStr)
 	case parser.LangRust:
-		// crate:: or super::
-		if strings.HasPrefix(importStr, "crate::") {
-			// Try to find Cargo.toml logic? simplified:
-			return strings.Replace(importStr, "crate::", "", 1)
-		}
-		return importStr
+		return a.resolveRustImport(sourcePath, importStr)
+	case parser.LangPHP:
+		return a.resolvePHPImport(sourcePath, importStr)
 	default:
 		// Basic relative fallback
 		if strings.HasPrefix(importStr, ".") {
@@ -245,158 +401,25 @@ func (a *Analyzer) resolveImport(sourcePath, importStr string, lang parser.Langu
 	}
 }
 
-func (a *Analyzer) resolveTSImport(sourcePath, importStr string) string {
-	// 1. Relative
-	if strings.HasPrefix(importStr, ".") {
-		return filepath.Join(filepath.Dir(sourcePath), importStr)
-	}
-
-	// 2. TSConfig Paths
-	// Find nearest tsconfig
-	dir := filepath.Dir(sourcePath)
-	var config TSConfig
-	var found bool
-
-	// Walk up to find tsconfig
-	for {
-		if c, ok := a.tsConfigs[dir]; ok {
-			config = c
-			found = true
-			break
-		}
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			break
-		}
-		dir = parent
-	}
-
-	if found {
-		// Check paths
-		for pattern, targets := range config.Paths {
-			// Simple exact match or wildcard
-			// "domain/*": ["src/domain/*"]
-			patternPrefix := strings.TrimSuffix(pattern, "*")
-			if strings.HasPrefix(importStr, patternPrefix) {
-				suffix := strings.TrimPrefix(importStr, patternPrefix)
-				if len(targets) > 0 {
-					target := targets[0] // take first
-					targetPrefix := strings.TrimSuffix(target, "*")
-					// Resolve relative to baseUrl (which is relative to tsconfig dir)
-					// Assumes baseUrl is "." or "src"
-					// This is complex. Simplified:
-					// If baseUrl is set, paths are relative to it.
-					// If not, relative to tsconfig.
-					base := config.BaseUrl
-					if base == "" {
-						base = "."
-					}
-					resolved := filepath.Join(dir, base, targetPrefix+suffix)
-					return resolved
-				}
-			}
-		}
-	}
-
-	return importStr
-}
-
-func (a *Analyzer) resolveGoImport(sourcePath, importStr string) string {
-	// Find nearest go.mod
-	dir := filepath.Dir(sourcePath)
-	var mod GoMod
-	var found bool
-
-	for {
-		if m, ok := a.goMods[dir]; ok {
-			mod = m
-			found = true
-			break
-		}
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			break
-		}
-		dir = parent
-	}
-
-	if found {
-		if strings.HasPrefix(importStr, mod.Module) {
-			rel := strings.TrimPrefix(importStr, mod.Module)
-			return filepath.Join(dir, rel)
-		}
-	}
-	return importStr
-}
-
 // FindViolations scans the graph for architectural inconsistencies and BDD drift.
-// It checks if code in the 'domain' layer imports 'infrastructure' or 'application' layers.
+// It checks every import between layers against the configured dependency matrix, optionally following
+// chains of imports through unlayered files, and every import between bounded contexts against their
+// public API. It also reports import cycles,
+// ports-and-adapters conformance issues, imports of Go internal packages and imports of external packages
+// forbidden by the ExternalRules.
 // It also verifies if Gherkin scenarios have matching step definitions.
+// Violations silenced by a `@hexanorm:ignore` annotation of their file are left out. The others get their
+// fingerprint, and are marked as baselined when the Baseline accepts them.
 func (a *Analyzer) FindViolations() []domain.Violation {
 	var violations []domain.Violation
 
-	nodes := a.Graph.GetAllNodes()
-	for _, node := range nodes {
-		if node.Kind == domain.NodeKindCode {
-			layer := node.Metadata["layer"]
-			if layer == nil {
-				continue
-			}
-			lStr := layer.(string)
-
-			// Get imports
-			edges := a.Graph.GetEdgesFrom(node.ID)
-			for _, edge := range edges {
-				if edge.Type == domain.EdgeTypeImports {
-					target, ok := a.Graph.GetNode(edge.TargetID)
-					// If we can't find the target node, we might try fuzzy matching or skip
-					// For now skip if not found (external lib)
-					if !ok {
-						// Heuristic: check if targetID looks like infra/app
-						if strings.Contains(edge.TargetID, "infrastructure") {
-							// Check rules
-							if lStr == "domain" {
-								violations = append(violations, domain.Violation{
-									Severity: domain.SeverityCritical,
-									Message:  fmt.Sprintf("Domain Rule Broken: '%s' imports '%s' (Infrastructure).", node.ID, edge.TargetID),
-									File:     node.ID,
-									Kind:     domain.ViolationKindArchLayer,
-								})
-							}
-						}
-						continue
-					}
-
-					targetLayer := target.Metadata["layer"]
-					if targetLayer == nil {
-						continue
-					}
-					tlStr := targetLayer.(string)
-
-					// Rule: Domain cannot import Infra or App
-					if lStr == "domain" {
-						if tlStr == "infrastructure" || tlStr == "application" {
-							violations = append(violations, domain.Violation{
-								Severity: domain.SeverityCritical,
-								Message:  fmt.Sprintf("Domain Rule Broken: '%s' imports '%s' (%s).", node.ID, target.ID, tlStr),
-								File:     node.ID,
-								Kind:     domain.ViolationKindArchLayer,
-							})
-						}
-					}
-					// Rule: App cannot import Infra (strict) or should use ports.
-					if lStr == "application" && tlStr == "infrastructure" {
-						violations = append(violations, domain.Violation{
-							Severity: domain.SeverityWarning,
-							Message:  fmt.Sprintf("Application Alert: '%s' imports '%s' (Infrastructure). Should use Ports.", node.ID, target.ID),
-							File:     node.ID,
-							Kind:     domain.ViolationKindArchLayer,
-						})
-					}
-				}
-			}
-		}
-	}
+	violations = append(violations, a.findLayerViolations()...)
+	violations = append(violations, a.findLayerLeaks()...)
+	violations = append(violations, a.findContextViolations()...)
+	violations = append(violations, a.findCycleViolations()...)
+	violations = append(violations, a.findPortViolations()...)
+	violations = append(violations, a.findInternalViolations()...)
+	violations = append(violations, a.findExternalViolations()...)
 
 	// BDD Drift Check
 	scenarios := a.filterNodes(domain.NodeKindGherkinScenario)
@@ -406,13 +429,9 @@ func (a *Analyzer) FindViolations() []domain.Violation {
 	paramRegistry := curex.NewParameterTypeRegistry()
 
 	for _, sc := range scenarios {
-		scSteps, ok := sc.Properties["steps"].([]string)
-		if !ok {
-			continue
-		}
-
-		for _, stepText := range scSteps {
-			cleanedStep := cleanStepText(stepText)
+		scSteps, scTexts := stepTexts(sc)
+		for i, stepText := range scSteps {
+			cleanedStep := scTexts[i]
 			matched := false
 			for _, sd := range stepDefs {
 				pattern, ok := sd.Properties["regex_pattern"].(string)
@@ -427,19 +446,111 @@ func (a *Analyzer) FindViolations() []domain.Violation {
 
 			if !matched {
 				violations = append(violations, domain.Violation{
-					Severity: domain.SeverityWarning,
-					Message:  fmt.Sprintf("BDD Drift/Missing: Step '%s' in '%s' has no matching StepDefinition.", stepText, sc.ID),
-					File:     sc.Properties["file"].(string),
-					Kind:     domain.ViolationKindBDDDrift,
-					Line:     sc.Properties["line"].(int),
+					Severity:    domain.SeverityWarning,
+					Message:     fmt.Sprintf("BDD Drift/Missing: Step '%s' in '%s' has no matching StepDefinition.", stepText, sc.ID),
+					File:        sc.Properties["file"].(string),
+					Target:      stepText,
+					Kind:        domain.ViolationKindBDDDrift,
+					Line:        intProp(sc.Properties["line"]),
+					RuleID:      "bdd/missing-step",
+					Remediation: fmt.Sprintf("Implement a step definition matching '%s', or fix the step text to match an existing one.", cleanedStep),
 				})
 			}
 		}
 	}
 
+	return a.applyBaseline(a.suppress(violations))
+}
+
+// findLayerViolations checks the IMPORTS edges of every layered code node against the configured LayerRules.
+func (a *Analyzer) findLayerViolations() []domain.Violation {
+	var violations []domain.Violation
+
+	for _, node := range a.filterNodes(domain.NodeKindCode) {
+		lStr, _ := node.Metadata["layer"].(string)
+		if lStr == "" {
+			continue
+		}
+
+		for _, edge := range a.Graph.GetEdgesFrom(node.ID) {
+			if edge.Type != domain.EdgeTypeImports {
+				continue
+			}
+
+			var tlStr string
+			if target, ok := a.Graph.GetNode(edge.TargetID); ok {
+				tlStr, _ = target.Metadata["layer"].(string)
+			} else {
+				// Unresolved target (external lib or unknown file): infer the layer from its path.
+				tlStr, _ = a.detectLayer(edge.TargetID)
+			}
+			if tlStr == "" {
+				continue
+			}
+
+			if allowed, severity := a.checkLayerRule(lStr, tlStr); !allowed {
+				violations = append(violations, atImport(domain.Violation{
+					Severity:    severity,
+					Message:     fmt.Sprintf("Layer Rule Broken: '%s' (%s) imports '%s' (%s).", node.ID, lStr, edge.TargetID, tlStr),
+					File:        node.ID,
+					Target:      edge.TargetID,
+					Kind:        domain.ViolationKindArchLayer,
+					RuleID:      "layer/" + lStr,
+					Remediation: fmt.Sprintf("Depend on an abstraction declared in the %s layer (a port) and move the %s dependency behind an adapter, or allow it in layer_rules.", lStr, tlStr),
+				}, node, edge.TargetID))
+			}
+		}
+	}
+
 	return violations
 }
 
+// sourceRange is the position of an import in a file, see parser.Import.
+type sourceRange struct {
+	Line      int `json:"line"`
+	Column    int `json:"column"`
+	EndLine   int `json:"end_line"`
+	EndColumn int `json:"end_column"`
+}
+
+// atImport locates a violation at the statement of the file node importing the target, if it is known.
+func atImport(v domain.Violation, node *domain.Node, targetID string) domain.Violation {
+	positions, _ := node.Properties["import_positions"].(map[string]interface{})
+	if pos, ok := positions[targetID].(map[string]interface{}); ok {
+		v.Line, v.Column = intProp(pos["line"]), intProp(pos["column"])
+		v.EndLine, v.EndColumn = intProp(pos["end_line"]), intProp(pos["end_column"])
+	}
+	return v
+}
+
+// checkLayerRule reports whether code in sourceLayer may import code in targetLayer.
+// If the import is forbidden, it also returns the severity of the broken rule.
+func (a *Analyzer) checkLayerRule(sourceLayer, targetLayer string) (bool, domain.ViolationSeverity) {
+	if sourceLayer == targetLayer {
+		return true, ""
+	}
+
+	var rule *config.LayerRule
+	for i, r := range a.Config.LayerRules {
+		if r.Layer != "*" && r.Layer != sourceLayer {
+			continue
+		}
+		for _, allowed := range r.MayImport {
+			if allowed == "*" || allowed == targetLayer {
+				return true, ""
+			}
+		}
+		if r.Layer == sourceLayer {
+			rule = &a.Config.LayerRules[i]
+		}
+	}
+
+	if rule == nil {
+		return true, ""
+	}
+	return false, rule.Severity
+}
+
 // IndexStepDefinitions tries to link Scenarios to Steps by matching step text to regex patterns.
 // It creates EXECUTES edges in the graph for matches found.
 func (a *Analyzer) IndexStepDefinitions() {
@@ -449,13 +560,8 @@ func (a *Analyzer) IndexStepDefinitions() {
 	paramRegistry := curex.NewParameterTypeRegistry()
 
 	for _, sc := range scenarios {
-		scSteps, ok := sc.Properties["steps"].([]string)
-		if !ok {
-			continue
-		}
-
-		for _, stepText := range scSteps {
-			cleanedStep := cleanStepText(stepText)
+		_, scTexts := stepTexts(sc)
+		for _, cleanedStep := range scTexts {
 
 			for _, sd := range stepDefs {
 				pattern, ok := sd.Properties["regex_pattern"].(string)
@@ -471,6 +577,19 @@ func (a *Analyzer) IndexStepDefinitions() {
 	}
 }
 
+// stepTexts returns the steps of a scenario as written, and their text without keywords.
+func stepTexts(sc *domain.Node) ([]string, []string) {
+	steps := stringSlice(sc.Properties["steps"])
+	texts := stringSlice(sc.Properties["step_texts"])
+	if len(texts) != len(steps) {
+		texts = make([]string, len(steps))
+		for i, step := range steps {
+			texts[i] = cleanStepText(step)
+		}
+	}
+	return steps, texts
+}
+
 func cleanStepText(step string) string {
 	parts := strings.Fields(step)
 	if len(parts) > 1 {
@@ -508,3 +627,21 @@ func (a *Analyzer) filterNodes(kind domain.NodeKind) []*domain.Node {
 	}
 	return res
 }
+
+// withProperties returns a copy of a node with the given properties set, or removed if their value is nil,
+// to be added to the graph in its place. Nodes in the graph are never modified, as they may be read concurrently.
+func withProperties(n *domain.Node, props map[string]interface{}) *domain.Node {
+	updated := *n
+	updated.Properties = make(map[string]interface{}, len(n.Properties)+len(props))
+	for k, v := range n.Properties {
+		updated.Properties[k] = v
+	}
+	for k, v := range props {
+		if v == nil {
+			delete(updated.Properties, k)
+		} else {
+			updated.Properties[k] = v
+		}
+	}
+	return &updated
+}
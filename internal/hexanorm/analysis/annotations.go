@@ -0,0 +1,138 @@
+package analysis
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/parser"
+)
+
+// Directives of the `@hexanorm:` source annotations.
+const (
+	directiveImplements = "implements" // `@hexanorm:implements REQ-42 REQ-43`
+	directiveLayer      = "layer"      // `@hexanorm:layer application`
+	directiveIgnore     = "ignore"     // `@hexanorm:ignore ARCH_LAYER_VIOLATION reason="legacy"`
+)
+
+// suppression silences the violations of the given kinds, or of every kind, reported for a file.
+type suppression struct {
+	Kinds  []string `json:"kinds,omitempty"`
+	Reason string   `json:"reason,omitempty"`
+	Line   int      `json:"line"`
+}
+
+// annotatedLayer returns the layer declared by a `@hexanorm:layer` annotation, or "" if there is none.
+func annotatedLayer(annotations []parser.Annotation) string {
+	for _, an := range annotations {
+		if an.Directive == directiveLayer && len(an.Args) > 0 {
+			return an.Args[0]
+		}
+	}
+	return ""
+}
+
+// analyzeAnnotations applies the `@hexanorm:implements` and `@hexanorm:ignore` annotations of a code file.
+// Requirements get an IMPLEMENTED_BY edge to the symbol declared right below the annotation, or to the file,
+// replacing the links of the previous version of the file. Suppressions are recorded on the file node,
+// whose updated version is returned.
+func (a *Analyzer) analyzeAnnotations(file *domain.Node, annotations []parser.Annotation, previous []string) *domain.Node {
+	for _, id := range previous {
+		for _, edge := range a.Graph.GetEdgesFrom(id) {
+			if edge.Type == domain.EdgeTypeImplementedBy && (edge.TargetID == file.ID || strings.HasPrefix(edge.TargetID, file.ID+"#")) {
+				a.Graph.RemoveEdge(id, edge.TargetID, domain.EdgeTypeImplementedBy)
+			}
+		}
+	}
+
+	var requirements []string
+	var suppressions []suppression
+	for _, an := range annotations {
+		switch an.Directive {
+		case directiveImplements:
+			target := a.annotatedSymbol(file.ID, an.TargetLine)
+			for _, id := range an.Args {
+				a.ensureRequirement(id, "Requirement referenced by @hexanorm:implements in "+file.ID)
+				a.Graph.AddEdge(id, target, domain.EdgeTypeImplementedBy)
+				requirements = appendUnique(requirements, id)
+			}
+		case directiveIgnore:
+			suppressions = append(suppressions, suppression{Kinds: an.Args, Reason: an.Params["reason"], Line: an.Line})
+		}
+	}
+
+	if len(requirements) == 0 && len(suppressions) == 0 && len(previous) == 0 {
+		return file
+	}
+	props := map[string]interface{}{"implements": requirements, "suppressions": nil}
+	if len(suppressions) > 0 {
+		props["suppressions"] = propertiesList(suppressions)
+	}
+	file = withProperties(file, props)
+	a.Graph.AddNode(file)
+	return file
+}
+
+// annotatedSymbol returns the ID of the symbol of the file declared on the given line, or the file itself.
+func (a *Analyzer) annotatedSymbol(path string, line int) string {
+	if line == 0 {
+		return path
+	}
+	for _, id := range a.containedSymbols(path) {
+		if n, ok := a.Graph.GetNode(id); ok && intProp(n.Properties["start_line"]) == line {
+			return id
+		}
+	}
+	return path
+}
+
+// requirementsAnnotatedIn returns the IDs of the requirements linked by the annotations of a file.
+func (a *Analyzer) requirementsAnnotatedIn(path string) []string {
+	if n, ok := a.Graph.GetNode(path); ok {
+		return stringSlice(n.Properties["implements"])
+	}
+	return nil
+}
+
+// suppressionsOf returns the suppressions recorded on a node, as stored in memory or loaded back from the store.
+func suppressionsOf(n *domain.Node) []suppression {
+	var res []suppression
+	if data, err := json.Marshal(n.Properties["suppressions"]); err == nil {
+		json.Unmarshal(data, &res)
+	}
+	return res
+}
+
+// propertiesList converts a list of structs into a node property using their JSON field names.
+func propertiesList(v interface{}) []interface{} {
+	var list []interface{}
+	if data, err := json.Marshal(v); err == nil {
+		json.Unmarshal(data, &list)
+	}
+	return list
+}
+
+// suppress drops the violations silenced by a `@hexanorm:ignore` annotation of their file.
+func (a *Analyzer) suppress(violations []domain.Violation) []domain.Violation {
+	res := violations[:0]
+	for _, v := range violations {
+		if !a.isSuppressed(v) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// isSuppressed reports whether a violation is silenced by an annotation of its file.
+func (a *Analyzer) isSuppressed(v domain.Violation) bool {
+	n, ok := a.Graph.GetNode(v.File)
+	if !ok {
+		return false
+	}
+	for _, s := range suppressionsOf(n) {
+		if len(s.Kinds) == 0 || contains(s.Kinds, string(v.Kind)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package analysis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+)
+
+// baselineDateLayout is the layout of the dates of baseline entries.
+const baselineDateLayout = "2006-01-02"
+
+// nodeIDPrefixes are the prefixes of the node IDs built from a file path, e.g. `port:src/ports.ts#Mailer`.
+var nodeIDPrefixes = []string{"port:", "adapter:", "test:"}
+
+// Baseline lists the violations accepted as existing debt, so that only new violations fail a check.
+// It is written to the repository, see Config.BaselineFile.
+type Baseline struct {
+	Entries []BaselineEntry `json:"entries"`
+}
+
+// BaselineEntry records an accepted violation. Paths are relative to the root of the repository.
+type BaselineEntry struct {
+	Fingerprint   string               `json:"fingerprint"`             // The violation's fingerprint.
+	Kind          domain.ViolationKind `json:"kind"`                    // The category of the violation.
+	File          string               `json:"file"`                    // The file associated with the violation.
+	Target        string               `json:"target,omitempty"`        // What the file breaks the rule with.
+	Message       string               `json:"message"`                 // The message of the violation when it was accepted.
+	Added         string               `json:"added,omitempty"`         // The date the violation was accepted, as YYYY-MM-DD.
+	Expires       string               `json:"expires,omitempty"`       // The date the acceptance ends, as YYYY-MM-DD, if any.
+	Justification string               `json:"justification,omitempty"` // Why the violation is accepted, e.g. a ticket.
+}
+
+// LoadBaseline reads a baseline file. A missing file is an empty baseline.
+// Entries whose dates are not formatted as YYYY-MM-DD are an error, as they would never or always expire.
+func LoadBaseline(path string) (*Baseline, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Baseline{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(content, &b); err != nil {
+		return nil, err
+	}
+	for _, e := range b.Entries {
+		for _, d := range [][2]string{{"added", e.Added}, {"expires", e.Expires}} {
+			if _, err := time.Parse(baselineDateLayout, d[1]); d[1] != "" && err != nil {
+				return nil, fmt.Errorf("baseline entry %s: invalid %s date %q, expected YYYY-MM-DD", e.Fingerprint, d[0], d[1])
+			}
+		}
+	}
+	return &b, nil
+}
+
+// Save writes the baseline to a file, with the entries sorted by file, kind and target.
+func (b *Baseline) Save(path string) error {
+	sort.Slice(b.Entries, func(i, j int) bool {
+		ei, ej := b.Entries[i], b.Entries[j]
+		if ei.File != ej.File {
+			return ei.File < ej.File
+		}
+		if ei.Kind != ej.Kind {
+			return ei.Kind < ej.Kind
+		}
+		return ei.Target < ej.Target
+	})
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
+
+// Accepts reports whether the baseline has an unexpired entry for the fingerprint on the given date.
+// An entry expires at the end of its Expires day.
+func (b *Baseline) Accepts(fingerprint string, now time.Time) bool {
+	if b == nil {
+		return false
+	}
+	today := now.Format(baselineDateLayout)
+	for _, e := range b.Entries {
+		if e.Fingerprint == fingerprint && (e.Expires == "" || e.Expires >= today) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewBaseline returns a baseline accepting the violations, as fingerprinted by FindViolations.
+// Entries of the previous baseline that are still violated keep their dates and justification, and the
+// others get the given ones. Entries that are no longer violated are dropped.
+func (a *Analyzer) NewBaseline(violations []domain.Violation, previous *Baseline, expires, justification string, now time.Time) *Baseline {
+	kept := make(map[string]BaselineEntry)
+	if previous != nil {
+		for _, e := range previous.Entries {
+			kept[e.Fingerprint] = e
+		}
+	}
+
+	b := &Baseline{Entries: []BaselineEntry{}}
+	seen := make(map[string]bool)
+	for _, v := range violations {
+		if seen[v.Fingerprint] {
+			continue
+		}
+		seen[v.Fingerprint] = true
+		entry := BaselineEntry{
+			Fingerprint:   v.Fingerprint,
+			Kind:          v.Kind,
+			File:          a.relativePath(v.File),
+			Target:        a.relativeTarget(v.Target),
+			Message:       v.Message,
+			Added:         now.Format(baselineDateLayout),
+			Expires:       expires,
+			Justification: justification,
+		}
+		if old, ok := kept[v.Fingerprint]; ok {
+			entry.Added, entry.Expires, entry.Justification = old.Added, old.Expires, old.Justification
+		}
+		b.Entries = append(b.Entries, entry)
+	}
+	return b
+}
+
+// applyBaseline fingerprints the violations and marks those the Baseline accepts.
+func (a *Analyzer) applyBaseline(violations []domain.Violation) []domain.Violation {
+	now := time.Now()
+	for i := range violations {
+		v := &violations[i]
+		v.Fingerprint = a.fingerprint(*v)
+		v.Baselined = a.Baseline.Accepts(v.Fingerprint, now)
+	}
+	return violations
+}
+
+// fingerprint identifies a violation by its kind, file and target, with paths relative to the RootDir,
+// so that it survives unrelated changes, line moves and checkouts in other directories.
+func (a *Analyzer) fingerprint(v domain.Violation) string {
+	sum := sha256.Sum256([]byte(string(v.Kind) + "\x00" + a.relativePath(v.File) + "\x00" + a.relativeTarget(v.Target)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// relativePath returns a path, or a node ID built from one, relative to the RootDir, with slashes.
+// The `port:`-like prefix and the `#Symbol` fragment of node IDs are kept, so that IDs are relative whether
+// the RootDir is absolute or not. Paths outside the RootDir and other strings are returned as is.
+func (a *Analyzer) relativePath(id string) string {
+	prefix, path, fragment := "", id, ""
+	for _, p := range nodeIDPrefixes {
+		if strings.HasPrefix(path, p) {
+			prefix, path = p, path[len(p):]
+			break
+		}
+	}
+	if i := strings.Index(path, "#"); i >= 0 {
+		path, fragment = path[:i], path[i:]
+	}
+	if a.RootDir == "" || path == "" {
+		return prefix + filepath.ToSlash(path) + fragment
+	}
+	rel, err := filepath.Rel(a.RootDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return prefix + filepath.ToSlash(path) + fragment
+	}
+	return prefix + filepath.ToSlash(rel) + fragment
+}
+
+// relativeTarget returns the target of a violation with its paths relative to the RootDir, including those of a cycle.
+func (a *Analyzer) relativeTarget(target string) string {
+	parts := strings.Split(target, " -> ")
+	for i, p := range parts {
+		parts[i] = a.relativePath(p)
+	}
+	return strings.Join(parts, " -> ")
+}
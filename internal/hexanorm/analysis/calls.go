@@ -0,0 +1,312 @@
+package analysis
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/parser"
+)
+
+// selfReceivers are the receivers designating the type declaring the calling method.
+var selfReceivers = []string{"this", "self", "$this", "static", "Self"}
+
+// analyzeCalls records on each function, method, step definition and test of a code file the calls made in its body,
+// in `receiver.name` form. IndexCallGraph resolves them into CALLS edges.
+func (a *Analyzer) analyzeCalls(path string, content []byte, lang parser.Language) {
+	calls, err := parser.ParseCalls(content, lang)
+	if err != nil {
+		return
+	}
+
+	var functions, stepDefs, tests []*domain.Node
+	for _, id := range a.containedSymbols(path) {
+		if n, ok := a.Graph.GetNode(id); ok && isCallable(n) {
+			functions = append(functions, n)
+		}
+	}
+	for _, n := range a.filterNodes(domain.NodeKindStepDefinition) {
+		if n.Properties["filepath"] == path {
+			stepDefs = append(stepDefs, n)
+		}
+	}
+	for _, id := range a.testsOf(path) {
+		if n, ok := a.Graph.GetNode(id); ok {
+			tests = append(tests, n)
+		}
+	}
+
+	made := make(map[string][]string)
+	for _, call := range calls {
+		// Calls are made by the innermost function enclosing them
+		var caller *domain.Node
+		for _, fn := range functions {
+			if spans(fn, "start_line", call.Line) && (caller == nil || intProp(fn.Properties["start_line"]) > intProp(caller.Properties["start_line"])) {
+				caller = fn
+			}
+		}
+		if caller != nil {
+			made[caller.ID] = appendUnique(made[caller.ID], call.Callee())
+		}
+		// ...and by the step definitions whose handler encloses them
+		for _, sd := range stepDefs {
+			if spans(sd, "line", call.Line) {
+				made[sd.ID] = appendUnique(made[sd.ID], call.Callee())
+			}
+		}
+		// ...and by the tests enclosing them
+		for _, test := range tests {
+			if spans(test, "start_line", call.Line) {
+				made[test.ID] = appendUnique(made[test.ID], call.Callee())
+			}
+		}
+	}
+
+	for _, n := range append(append(functions, stepDefs...), tests...) {
+		a.Graph.AddNode(withProperties(n, map[string]interface{}{"calls": made[n.ID]}))
+	}
+}
+
+// IndexCallGraph resolves the calls recorded on functions, methods and step definitions into CALLS edges
+// to the symbols they target. Tests get VERIFIES edges instead, to the symbols they call and to the files
+// or Go packages they import. Calls are resolved against the symbols of the caller's file (its package, for Go)
+// and of the files it imports:
+//   - `this.m()`, `self.m()`: method of the caller's type;
+//   - `f()`: function declared locally or imported;
+//   - `Type.m()`, `Type::m()`: method of a type declared locally or imported;
+//   - `module.f()`: function of the imported module or package named like the receiver;
+//   - `value.m()`: the only method with that name among the local and imported types.
+func (a *Analyzer) IndexCallGraph() {
+	var callers []*domain.Node
+	for _, n := range a.filterNodes(domain.NodeKindSymbol) {
+		if isCallable(n) {
+			callers = append(callers, n)
+		}
+	}
+	callers = append(callers, a.filterNodes(domain.NodeKindStepDefinition)...)
+	callers = append(callers, a.filterNodes(domain.NodeKindTest)...)
+	a.indexCalls(callers)
+}
+
+// IndexCallGraphOf resolves again the calls of a changed file, and of the files that may call into it:
+// those importing it or its Go package, and the other files of its Go package.
+func (a *Analyzer) IndexCallGraphOf(path string) {
+	files := map[string]bool{path: true}
+	targets := []string{path, strings.TrimSuffix(path, filepath.Ext(path))}
+	if parser.DetectLanguage(path) == parser.LangGo {
+		dir := filepath.Dir(path)
+		targets = append(targets, dir)
+		for _, edge := range a.Graph.GetEdgesFrom(dir) {
+			if edge.Type == domain.EdgeTypeContains {
+				files[edge.TargetID] = true
+			}
+		}
+	}
+	for _, target := range targets {
+		for _, edge := range a.Graph.GetEdgesTo(target) {
+			if edge.Type == domain.EdgeTypeImports {
+				files[edge.SourceID] = true
+			}
+		}
+	}
+
+	var callers []*domain.Node
+	for file := range files {
+		for _, id := range append(a.containedSymbols(file), a.testsOf(file)...) {
+			if n, ok := a.Graph.GetNode(id); ok && (n.Kind == domain.NodeKindTest || n.Kind == domain.NodeKindSymbol && isCallable(n)) {
+				callers = append(callers, n)
+			}
+		}
+	}
+	for _, n := range a.filterNodes(domain.NodeKindStepDefinition) {
+		if file, _ := n.Properties["filepath"].(string); files[file] {
+			callers = append(callers, n)
+		}
+	}
+	a.indexCalls(callers)
+}
+
+// indexCalls replaces the CALLS edges of the callers, or the VERIFIES edges of tests, with those of their resolved calls.
+func (a *Analyzer) indexCalls(callers []*domain.Node) {
+	idx := a.newSymbolIndex()
+	for _, n := range callers {
+		edgeType := domain.EdgeTypeCalls
+		if n.Kind == domain.NodeKindTest {
+			edgeType = domain.EdgeTypeVerifies
+		}
+		a.Graph.RemoveEdgesFrom(n.ID, edgeType)
+		file, _ := n.Properties["file"].(string)
+		if n.Kind == domain.NodeKindStepDefinition {
+			file, _ = n.Properties["filepath"].(string)
+			a.Graph.AddEdge(n.ID, file, domain.EdgeTypeCalls)
+		}
+
+		scope := idx.local(file)
+		imports := make(map[string][]*domain.Node)
+		for _, edge := range a.Graph.GetEdgesFrom(file) {
+			if edge.Type != domain.EdgeTypeImports {
+				continue
+			}
+			imports[edge.TargetID] = idx.in(edge.TargetID)
+			if n.Kind == domain.NodeKindTest {
+				if target, ok := a.Graph.GetNode(edge.TargetID); ok && (target.Kind == domain.NodeKindCode || target.Kind == domain.NodeKindPackage) {
+					a.Graph.AddEdge(n.ID, target.ID, domain.EdgeTypeVerifies)
+				}
+			}
+		}
+
+		for _, call := range stringSlice(n.Properties["calls"]) {
+			for _, target := range resolveCall(n, call, scope, imports) {
+				if target.ID != n.ID {
+					a.Graph.AddEdge(n.ID, target.ID, edgeType)
+				}
+			}
+		}
+	}
+}
+
+// resolveCall returns the symbols targeted by a call made by the caller.
+func resolveCall(caller *domain.Node, call string, scope []*domain.Node, imports map[string][]*domain.Node) []*domain.Node {
+	receiver, name := "", call
+	if i := strings.LastIndex(call, "."); i >= 0 {
+		receiver, name = call[:i], call[i+1:]
+	}
+
+	all := scope
+	targets := make([]string, 0, len(imports))
+	for target, symbols := range imports {
+		all = append(all, symbols...)
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	switch {
+	case contains(selfReceivers, receiver):
+		return findSymbols(scope, ownerType(caller)+"."+name)
+	case receiver == "":
+		if found := findSymbols(scope, name); len(found) > 0 {
+			return found
+		}
+		// Go functions of other packages are always called through the package name
+		if caller.Metadata["language"] == string(parser.LangGo) {
+			return nil
+		}
+		var found []*domain.Node
+		for _, target := range targets {
+			found = append(found, findSymbols(imports[target], name)...)
+		}
+		return found
+	case receiver == parser.UnknownReceiver:
+	default:
+		if found := findSymbols(all, receiver+"."+name); len(found) > 0 {
+			return found
+		}
+		for _, target := range targets {
+			if moduleName(target) == receiver {
+				if found := findSymbols(imports[target], name); len(found) > 0 {
+					return found
+				}
+			}
+		}
+	}
+
+	var methods []*domain.Node
+	for _, s := range all {
+		if s.Properties["symbol_kind"] == string(parser.SymbolKindMethod) && s.Properties["name"] == name {
+			methods = append(methods, s)
+		}
+	}
+	if len(methods) == 1 {
+		return methods
+	}
+	return nil
+}
+
+// symbolIndex indexes Symbol nodes by the targets of import edges.
+type symbolIndex struct {
+	byFile map[string][]*domain.Node // File path, with and without extension -> symbols
+	byDir  map[string][]*domain.Node // Go package directory -> symbols
+}
+
+func (a *Analyzer) newSymbolIndex() *symbolIndex {
+	idx := &symbolIndex{
+		byFile: make(map[string][]*domain.Node),
+		byDir:  make(map[string][]*domain.Node),
+	}
+	symbols := a.filterNodes(domain.NodeKindSymbol)
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].ID < symbols[j].ID })
+	for _, s := range symbols {
+		file, _ := s.Properties["file"].(string)
+		idx.byFile[file] = append(idx.byFile[file], s)
+		if ext := filepath.Ext(file); ext != "" {
+			idx.byFile[strings.TrimSuffix(file, ext)] = append(idx.byFile[strings.TrimSuffix(file, ext)], s)
+		}
+		if s.Metadata["language"] == string(parser.LangGo) {
+			idx.byDir[filepath.Dir(file)] = append(idx.byDir[filepath.Dir(file)], s)
+		}
+	}
+	return idx
+}
+
+// local returns the symbols visible without import from a file: those of its package for Go, of the file otherwise.
+func (idx *symbolIndex) local(file string) []*domain.Node {
+	if parser.DetectLanguage(file) == parser.LangGo {
+		return idx.byDir[filepath.Dir(file)]
+	}
+	return idx.byFile[file]
+}
+
+// in returns the symbols declared in an import target: a file, or a Go package directory.
+func (idx *symbolIndex) in(target string) []*domain.Node {
+	if symbols, ok := idx.byFile[target]; ok {
+		return symbols
+	}
+	return idx.byDir[target]
+}
+
+// findSymbols returns the symbols with the given qualified name.
+func findSymbols(symbols []*domain.Node, qualifiedName string) []*domain.Node {
+	var found []*domain.Node
+	for _, s := range symbols {
+		if s.Properties["qualified_name"] == qualifiedName {
+			found = append(found, s)
+		}
+	}
+	return found
+}
+
+// isCallable reports whether a symbol is a function or a method.
+func isCallable(n *domain.Node) bool {
+	kind := n.Properties["symbol_kind"]
+	return kind == string(parser.SymbolKindFunction) || kind == string(parser.SymbolKindMethod)
+}
+
+// ownerType returns the name of the type declaring a method symbol.
+func ownerType(n *domain.Node) string {
+	qualified, _ := n.Properties["qualified_name"].(string)
+	name, _ := n.Properties["name"].(string)
+	return strings.TrimSuffix(strings.TrimSuffix(qualified, name), ".")
+}
+
+// moduleName returns the name under which an import target is referenced: its file or directory name.
+func moduleName(target string) string {
+	base := filepath.Base(target)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// spans reports whether a line falls within the range of a node, starting at the given property and ending at end_line.
+func spans(n *domain.Node, startProperty string, line int) bool {
+	return intProp(n.Properties[startProperty]) <= line && line <= intProp(n.Properties["end_line"])
+}
+
+// intProp returns an integer property, which the store loads back as a float64.
+func intProp(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	}
+	return 0
+}
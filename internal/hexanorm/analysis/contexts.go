@@ -0,0 +1,158 @@
+package analysis
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/config"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+)
+
+// contextMatcher is a compiled bounded-context definition from the configuration.
+type contextMatcher struct {
+	name      string
+	patterns  []*regexp.Regexp
+	publicAPI []*regexp.Regexp
+}
+
+// compileContextMatchers compiles the declared bounded contexts and the global public API patterns.
+// Invalid patterns are skipped; LoadConfig reports them when the configuration is read.
+func compileContextMatchers(cfg *config.Config) ([]contextMatcher, []*regexp.Regexp) {
+	var contexts []contextMatcher
+	for _, d := range cfg.Contexts.Definitions {
+		contexts = append(contexts, contextMatcher{
+			name:      d.Name,
+			patterns:  compilePatterns(d.Patterns),
+			publicAPI: compilePatterns(d.PublicAPI),
+		})
+	}
+	return contexts, compilePatterns(cfg.Contexts.PublicAPI)
+}
+
+// compilePatterns compiles a list of configuration path patterns, skipping invalid ones.
+func compilePatterns(patterns []string) []*regexp.Regexp {
+	var res []*regexp.Regexp
+	for _, p := range patterns {
+		if re, err := config.CompilePattern(p); err == nil {
+			res = append(res, re)
+		}
+	}
+	return res
+}
+
+// dirPatterns returns the path patterns matching every file under the given directories.
+func dirPatterns(dirs []string) []string {
+	patterns := make([]string, len(dirs))
+	for i, d := range dirs {
+		patterns[i] = strings.TrimSuffix(d, "/") + "/**"
+	}
+	return patterns
+}
+
+// matchesAny reports whether the path matches any of the compiled patterns.
+func matchesAny(patterns []*regexp.Regexp, path string) bool {
+	slashed := filepath.ToSlash(path)
+	for _, re := range patterns {
+		if re.MatchString(slashed) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectContext returns the bounded context of a path, or an empty string if it belongs to none.
+// Declared contexts win. Otherwise the context is inferred from the layer pattern that matched the path:
+// either its explicit `context` group, or the first directory after a trailing `/**`.
+func (a *Analyzer) detectContext(path string) string {
+	for _, c := range a.contextMatchers {
+		if matchesAny(c.patterns, path) {
+			return c.name
+		}
+	}
+
+	if a.Config.Contexts.DisableInference {
+		return ""
+	}
+	m, sub := a.matchLayer(path)
+	if m == nil {
+		return ""
+	}
+	if i := m.re.SubexpIndex(config.ContextGroup); i >= 0 {
+		return sub[i]
+	}
+	if !contains(a.Config.Contexts.InferLayers, m.layer) {
+		return ""
+	}
+	if i := m.re.SubexpIndex(config.RestGroup); i >= 0 {
+		// Files directly inside the layer directory belong to no context.
+		if ctx, _, found := strings.Cut(sub[i], "/"); found {
+			return ctx
+		}
+	}
+	return ""
+}
+
+// isContextPublic reports whether a file of the given context may be imported by other contexts.
+func (a *Analyzer) isContextPublic(context, path string) bool {
+	if contains(a.Config.Contexts.SharedKernel, context) || matchesAny(a.contextPublicAPI, path) {
+		return true
+	}
+	for _, c := range a.contextMatchers {
+		if c.name == context && matchesAny(c.publicAPI, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// findContextViolations reports imports between bounded contexts that bypass the target's public API.
+func (a *Analyzer) findContextViolations() []domain.Violation {
+	var violations []domain.Violation
+
+	for _, node := range a.filterNodes(domain.NodeKindCode) {
+		ctx, _ := node.Metadata["context"].(string)
+		if ctx == "" {
+			continue
+		}
+
+		for _, edge := range a.Graph.GetEdgesFrom(node.ID) {
+			if edge.Type != domain.EdgeTypeImports {
+				continue
+			}
+
+			var targetCtx string
+			if target, ok := a.Graph.GetNode(edge.TargetID); ok {
+				targetCtx, _ = target.Metadata["context"].(string)
+			} else {
+				targetCtx = a.detectContext(edge.TargetID)
+			}
+			if targetCtx == "" || targetCtx == ctx || a.isContextPublic(targetCtx, edge.TargetID) {
+				continue
+			}
+
+			violations = append(violations, atImport(domain.Violation{
+				Severity:    a.Config.Contexts.Severity,
+				Message:     fmt.Sprintf("Context Rule Broken: '%s' (%s) imports '%s' from the internals of context '%s'.", node.ID, ctx, edge.TargetID, targetCtx),
+				File:        node.ID,
+				Target:      edge.TargetID,
+				Kind:        domain.ViolationKindArchContext,
+				RuleID:      "context/" + targetCtx,
+				Remediation: fmt.Sprintf("Import the public API of context '%s' instead, or declare the imported file in its public_api.", targetCtx),
+			}, node, edge.TargetID))
+		}
+	}
+
+	return violations
+}
+
+// contains reports whether the slice contains the given string.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package analysis
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/graph"
+)
+
+// findCycleViolations reports import cycles at file, package (directory) and bounded-context granularity,
+// one per strongly connected component: its shortest cycle, and all its members when there are more.
+// Go files import packages, not files, so Go cycles only show at package and context granularity.
+func (a *Analyzer) findCycleViolations() []domain.Violation {
+	files := make(map[string][]string)
+	packages := make(map[string][]string)
+	contexts := make(map[string][]string)
+
+	for _, node := range a.filterNodes(domain.NodeKindCode) {
+		ctx, _ := node.Metadata["context"].(string)
+		for _, edge := range a.Graph.GetEdgesFrom(node.ID) {
+			if edge.Type != domain.EdgeTypeImports {
+				continue
+			}
+			target, ok := a.Graph.GetNode(edge.TargetID)
+			if !ok || target.Kind == domain.NodeKindExternal {
+				continue
+			}
+			if target.Kind == domain.NodeKindCode {
+				files[node.ID] = append(files[node.ID], target.ID)
+			}
+
+			if src, dst := packageOf(node), packageOf(target); src != dst {
+				packages[src] = appendUnique(packages[src], dst)
+			}
+			if targetCtx, _ := target.Metadata["context"].(string); ctx != "" && targetCtx != "" && ctx != targetCtx {
+				contexts[ctx] = appendUnique(contexts[ctx], targetCtx)
+			}
+		}
+	}
+
+	var violations []domain.Violation
+	report := func(level string, adj map[string][]string) {
+		for _, component := range graph.StronglyConnectedComponents(adj) {
+			cycle := graph.ShortestCycle(adj, component)
+			if cycle == nil {
+				continue
+			}
+			message := fmt.Sprintf("Import Cycle (%s): %s", level, strings.Join(cycle, " -> "))
+			if len(component) > len(cycle)-1 {
+				// Other cycles go through the same component: list all of it
+				message += fmt.Sprintf(" (%d %ss are tangled: %s)", len(component), level, strings.Join(component, ", "))
+			}
+			v := domain.Violation{
+				Severity:    a.Config.CycleSeverity,
+				Message:     message,
+				File:        cycle[0],
+				Target:      strings.Join(cycle, " -> "),
+				Kind:        domain.ViolationKindImportCycle,
+				RuleID:      "cycle/" + level,
+				Remediation: fmt.Sprintf("Break the cycle by moving the shared code to a common %s, or by inverting one of the dependencies behind an interface.", level),
+			}
+			// File cycles start at the import of the next file
+			if node, ok := a.Graph.GetNode(cycle[0]); ok && level == "file" && len(cycle) > 1 {
+				v = atImport(v, node, cycle[1])
+			}
+			violations = append(violations, v)
+		}
+	}
+	report("file", files)
+	report("package", packages)
+	report("context", contexts)
+
+	return violations
+}
+
+// packageOf returns the package (directory) a code node belongs to, or the Go package itself.
+func packageOf(node *domain.Node) string {
+	if node.Kind == domain.NodeKindPackage {
+		return node.ID
+	}
+	return filepath.Dir(node.ID)
+}
+
+// appendUnique appends s to list unless it is already present.
+func appendUnique(list []string, s string) []string {
+	if contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
@@ -0,0 +1,175 @@
+package analysis
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/config"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/parser"
+)
+
+// stdlibPattern is the ExternalRule pattern matching the standard library of the importing language.
+const stdlibPattern = "stdlib"
+
+var (
+	// nodeBuiltins are the modules built into Node.js, which may also be imported with the `node:` scheme.
+	nodeBuiltins = []string{
+		"assert", "async_hooks", "buffer", "child_process", "cluster", "console", "crypto", "dgram", "dns",
+		"events", "fs", "http", "http2", "https", "net", "os", "path", "perf_hooks", "process", "querystring",
+		"readline", "stream", "string_decoder", "timers", "tls", "tty", "url", "util", "v8", "vm",
+		"worker_threads", "zlib",
+	}
+	// pythonStdlib are the commonly imported modules of the Python standard library.
+	pythonStdlib = []string{
+		"__future__", "abc", "argparse", "array", "asyncio", "base64", "collections", "concurrent", "contextlib",
+		"copy", "csv", "dataclasses", "datetime", "decimal", "email", "enum", "fractions", "functools", "glob",
+		"hashlib", "heapq", "html", "http", "inspect", "io", "itertools", "json", "logging", "math",
+		"multiprocessing", "operator", "os", "pathlib", "pickle", "queue", "random", "re", "secrets", "select",
+		"shutil", "signal", "socket", "sqlite3", "ssl", "statistics", "string", "struct", "subprocess", "sys",
+		"tempfile", "textwrap", "threading", "time", "traceback", "typing", "unittest", "urllib", "uuid",
+		"warnings", "weakref", "xml", "zoneinfo",
+	}
+	// rustStdlib are the crates shipped with the Rust toolchain.
+	rustStdlib = []string{"std", "core", "alloc", "proc_macro", "test"}
+	// jvmStdlib are the top-level packages of the JDK and of the Kotlin standard library.
+	jvmStdlib = []string{"java", "javax", "jdk", "kotlin"}
+)
+
+// isExternalImport reports whether an import resolved to no file of the project: resolvers return such
+// imports, e.g. `database/sql` or `aws-sdk`, as written. Relative and absolute paths are never external.
+func isExternalImport(importStr, targetID string) bool {
+	importStr = strings.Trim(importStr, "\"'`")
+	return targetID == importStr && importStr != "" && !strings.HasPrefix(importStr, ".") && !filepath.IsAbs(importStr)
+}
+
+// ensureExternal adds the External node of a package imported as written, unless the graph already has a node with its ID.
+func (a *Analyzer) ensureExternal(id string, lang parser.Language) {
+	if _, ok := a.Graph.GetNode(id); ok {
+		return
+	}
+	a.Graph.AddNode(&domain.Node{
+		ID:   id,
+		Kind: domain.NodeKindExternal,
+		Properties: map[string]interface{}{
+			"stdlib": isStdlib(id, lang),
+		},
+		Metadata: map[string]interface{}{
+			"language": string(lang),
+		},
+	})
+}
+
+// pruneExternal removes an External node once no file imports it anymore.
+func (a *Analyzer) pruneExternal(id string) {
+	if n, ok := a.Graph.GetNode(id); ok && n.Kind == domain.NodeKindExternal && len(a.Graph.GetEdgesTo(id)) == 0 {
+		a.Graph.RemoveNode(id)
+	}
+}
+
+// isStdlib reports whether an external package belongs to the standard library of the language.
+// Go packages whose path has no domain, PHP classes of the global namespace and the modules built into
+// Node.js count as standard.
+func isStdlib(pkg string, lang parser.Language) bool {
+	switch lang {
+	case parser.LangGo:
+		return !strings.Contains(strings.SplitN(pkg, "/", 2)[0], ".")
+	case parser.LangTypeScript, parser.LangTSX, parser.LangJavaScript:
+		return strings.HasPrefix(pkg, "node:") || contains(nodeBuiltins, strings.SplitN(pkg, "/", 2)[0])
+	case parser.LangPython:
+		return contains(pythonStdlib, strings.SplitN(pkg, ".", 2)[0])
+	case parser.LangRust:
+		return contains(rustStdlib, strings.SplitN(pkg, "::", 2)[0])
+	case parser.LangJava, parser.LangKotlin:
+		return contains(jvmStdlib, strings.SplitN(pkg, ".", 2)[0])
+	case parser.LangPHP:
+		return !strings.Contains(strings.TrimPrefix(pkg, "\\"), "\\")
+	default:
+		return false
+	}
+}
+
+// matchesPackage reports whether an external package matches an ExternalRule pattern: the package itself or one of
+// its sub-packages, whatever the language's separator, any package starting with the prefix of a pattern ending
+// with `*`, or the standard library for `stdlib`.
+func matchesPackage(pattern, pkg string, lang parser.Language) bool {
+	switch {
+	case pattern == stdlibPattern:
+		return isStdlib(pkg, lang)
+	case strings.HasSuffix(pattern, "*"):
+		return strings.HasPrefix(pkg, strings.TrimSuffix(pattern, "*"))
+	case pkg == pattern:
+		return true
+	case strings.HasPrefix(pkg, pattern):
+		rest := pkg[len(pattern):]
+		return strings.HasPrefix(rest, "/") || strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "::") || strings.HasPrefix(rest, "\\")
+	default:
+		return false
+	}
+}
+
+// checkExternalRule reports whether code in layer may import the external package.
+// If the import is forbidden, it also returns the broken rule.
+func (a *Analyzer) checkExternalRule(layer, pkg string, lang parser.Language) (bool, *config.ExternalRule) {
+	for i, r := range a.Config.ExternalRules {
+		if r.Layer != "*" && r.Layer != layer {
+			continue
+		}
+		for _, p := range r.Forbid {
+			if matchesPackage(p, pkg, lang) {
+				return false, &a.Config.ExternalRules[i]
+			}
+		}
+		if len(r.Allow) == 0 {
+			continue
+		}
+		allowed := false
+		for _, p := range r.Allow {
+			if matchesPackage(p, pkg, lang) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false, &a.Config.ExternalRules[i]
+		}
+	}
+	return true, nil
+}
+
+// findExternalViolations checks the imports of External nodes by every layered code node against the configured ExternalRules.
+func (a *Analyzer) findExternalViolations() []domain.Violation {
+	var violations []domain.Violation
+
+	for _, node := range a.filterNodes(domain.NodeKindCode) {
+		layer, _ := node.Metadata["layer"].(string)
+		if layer == "" {
+			continue
+		}
+		lang, _ := node.Metadata["language"].(string)
+
+		for _, edge := range a.Graph.GetEdgesFrom(node.ID) {
+			if edge.Type != domain.EdgeTypeImports {
+				continue
+			}
+			target, ok := a.Graph.GetNode(edge.TargetID)
+			if !ok || target.Kind != domain.NodeKindExternal {
+				continue
+			}
+			if allowed, rule := a.checkExternalRule(layer, target.ID, parser.Language(lang)); !allowed {
+				violations = append(violations, atImport(domain.Violation{
+					Severity:    rule.Severity,
+					Message:     fmt.Sprintf("External Dependency Forbidden: '%s' (%s) imports '%s'.", node.ID, layer, target.ID),
+					File:        node.ID,
+					Target:      target.ID,
+					Kind:        domain.ViolationKindExternalImport,
+					RuleID:      "external/" + layer,
+					Remediation: fmt.Sprintf("Wrap '%s' in an adapter of an outer layer behind a port of the %s layer, or allow it in external_rules.", target.ID, layer),
+				}, node, target.ID))
+			}
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,211 @@
+package analysis
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/parser"
+)
+
+// GoMod represents basic module information from go.mod.
+type GoMod struct {
+	Module string
+}
+
+// GoWork represents a go.work workspace: the directories of the modules it uses.
+type GoWork struct {
+	Modules []string
+}
+
+var goModule = regexp.MustCompile(`module\s+([^\s]+)`)
+
+func (a *Analyzer) parseGoMod(path string, content []byte) {
+	matches := goModule.FindSubmatch(content)
+	if len(matches) > 1 {
+		dir := filepath.Dir(path)
+		a.goMods[dir] = GoMod{Module: strings.Trim(string(matches[1]), `"`)}
+	}
+}
+
+// parseGoWork records the modules of a go.work, from single `use ./dir` directives and `use ( ... )` blocks.
+func (a *Analyzer) parseGoWork(path string, content []byte) {
+	dir := filepath.Dir(path)
+	var work GoWork
+	inBlock := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inBlock && fields[0] == ")":
+			inBlock = false
+			continue
+		case inBlock:
+		case fields[0] == "use" && len(fields) > 1 && fields[1] == "(":
+			inBlock = true
+			continue
+		case fields[0] == "use" && len(fields) > 1:
+			fields = fields[1:]
+		default:
+			continue
+		}
+		work.Modules = appendUnique(work.Modules, filepath.Join(dir, strings.Trim(fields[0], `"`)))
+	}
+	a.goWorks[dir] = work
+}
+
+// resolveGoImport resolves a Go import path to the directory of the package, which is the ID of its Package node.
+// The import is matched against the module of the nearest go.mod and the modules of the nearest go.work,
+// the longest module path winning. Imports of other modules, such as the standard library, are returned as written.
+func (a *Analyzer) resolveGoImport(sourcePath, importStr string) string {
+	var best, bestDir string
+	for _, dir := range a.goModuleDirs(sourcePath) {
+		module := a.goMods[dir].Module
+		if (importStr == module || strings.HasPrefix(importStr, module+"/")) && len(module) > len(best) {
+			best, bestDir = module, dir
+		}
+	}
+	if best == "" {
+		return importStr
+	}
+	return filepath.Join(bestDir, filepath.FromSlash(strings.TrimPrefix(importStr, best)))
+}
+
+// goModuleDirs returns the directories of the modules visible from a file: its own module,
+// followed by the modules of the workspace it belongs to.
+func (a *Analyzer) goModuleDirs(sourcePath string) []string {
+	var dirs []string
+	modFound, workFound := false, false
+	dir := filepath.Dir(sourcePath)
+	for {
+		if _, ok := a.goMods[dir]; ok && !modFound {
+			dirs, modFound = append(dirs, dir), true
+		}
+		if work, ok := a.goWorks[dir]; ok && !workFound {
+			for _, m := range work.Modules {
+				if _, ok := a.goMods[m]; ok {
+					dirs = appendUnique(dirs, m)
+				}
+			}
+			workFound = true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dirs
+		}
+		dir = parent
+	}
+}
+
+// goImportPath returns the import path of the package in dir, or "" if it belongs to no known module.
+func (a *Analyzer) goImportPath(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if mod, ok := a.goMods[d]; ok {
+			rel, err := filepath.Rel(d, dir)
+			if err != nil {
+				return ""
+			}
+			if rel == "." {
+				return mod.Module
+			}
+			return mod.Module + "/" + filepath.ToSlash(rel)
+		}
+		if filepath.Dir(d) == d {
+			return ""
+		}
+	}
+}
+
+// analyzeGoPackage adds the Package node of a Go file's directory, which CONTAINS the file and takes its layer
+// and context. Go imports target packages, so layer, context and cycle checks apply to them as to files.
+func (a *Analyzer) analyzeGoPackage(file *domain.Node) {
+	dir := filepath.Dir(file.ID)
+	pkg := &domain.Node{
+		ID:   dir,
+		Kind: domain.NodeKindPackage,
+		Properties: map[string]interface{}{
+			"import_path": a.goImportPath(dir),
+		},
+		Metadata: map[string]interface{}{
+			"layer":        file.Metadata["layer"],
+			"layer_source": file.Metadata["layer_source"],
+			"context":      file.Metadata["context"],
+			"language":     string(parser.LangGo),
+		},
+	}
+	a.Graph.AddNode(pkg)
+	a.Graph.AddEdge(dir, file.ID, domain.EdgeTypeContains)
+}
+
+// prunePackage removes the Package node of a directory once it contains no more files.
+func (a *Analyzer) prunePackage(dir string) {
+	if n, ok := a.Graph.GetNode(dir); ok && n.Kind == domain.NodeKindPackage &&
+		!hasEdgeOfType(a.Graph.GetEdgesFrom(dir), domain.EdgeTypeContains) {
+		a.Graph.RemoveNode(dir)
+	}
+}
+
+// findInternalViolations reports Go imports of `internal` packages from outside the tree rooted at the parent
+// of the `internal` directory, which the Go toolchain rejects.
+func (a *Analyzer) findInternalViolations() []domain.Violation {
+	var violations []domain.Violation
+
+	for _, node := range a.filterNodes(domain.NodeKindCode) {
+		if node.Metadata["language"] != string(parser.LangGo) {
+			continue
+		}
+		for _, edge := range a.Graph.GetEdgesFrom(node.ID) {
+			if edge.Type != domain.EdgeTypeImports {
+				continue
+			}
+			target, ok := a.Graph.GetNode(edge.TargetID)
+			if !ok || target.Kind != domain.NodeKindPackage {
+				continue
+			}
+			root, ok := internalRoot(target.ID)
+			if !ok || isWithin(filepath.Dir(node.ID), root) {
+				continue
+			}
+			violations = append(violations, atImport(domain.Violation{
+				Severity:    domain.SeverityCritical,
+				Message:     fmt.Sprintf("Internal Package Imported: '%s' imports '%s', which is only visible under '%s'.", node.ID, target.ID, root),
+				File:        node.ID,
+				Target:      target.ID,
+				Kind:        domain.ViolationKindInternalImport,
+				RuleID:      "go/internal",
+				Remediation: fmt.Sprintf("Use an exported package of '%s' instead, or move the package out of its internal directory.", root),
+			}, node, target.ID))
+		}
+	}
+
+	return violations
+}
+
+// internalRoot returns the parent of the last `internal` element of a package directory,
+// the only tree allowed to import it.
+func internalRoot(dir string) (string, bool) {
+	sep := string(filepath.Separator)
+	elems := strings.Split(dir, sep)
+	for i := len(elems) - 1; i >= 0; i-- {
+		if elems[i] == "internal" {
+			return strings.Join(elems[:i], sep), true
+		}
+	}
+	return "", false
+}
+
+// isWithin reports whether dir is root or one of its subdirectories.
+func isWithin(dir, root string) bool {
+	return dir == root || strings.HasPrefix(dir, root+string(filepath.Separator))
+}
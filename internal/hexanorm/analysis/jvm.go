@@ -0,0 +1,266 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+)
+
+// JVMProject represents the source roots of a Maven or Gradle project, relative to its directory.
+type JVMProject struct {
+	SourceRoots []string
+}
+
+// defaultSourceRoots are the Maven and Gradle conventional source roots, always tried after the declared ones.
+var defaultSourceRoots = []string{"src/main/java", "src/main/kotlin", "src/test/java", "src/test/kotlin"}
+
+// jvmExtensions are the extensions of the files a Java or Kotlin import may resolve to.
+var jvmExtensions = []string{".java", ".kt"}
+
+var (
+	mavenSourceDir = regexp.MustCompile(`<(?:test)?[sS]ourceDirectory>\s*([^<]+?)\s*</`)
+	gradleSrcDirs  = regexp.MustCompile(`srcDirs?\b[^\n'"]*((?:\s*,?\s*['"][^'"]+['"])+)`)
+	quoted         = regexp.MustCompile(`['"]([^'"]+)['"]`)
+)
+
+// isJVMBuildFile reports whether the file is a Maven or Gradle build file.
+func isJVMBuildFile(path string) bool {
+	switch filepath.Base(path) {
+	case "pom.xml", "build.gradle", "build.gradle.kts":
+		return true
+	}
+	return false
+}
+
+// parseJVMBuild records the source roots declared in a `pom.xml` (`<sourceDirectory>`, `<testSourceDirectory>`)
+// or a Gradle build file (`srcDir`, `srcDirs`), followed by the conventional ones.
+func (a *Analyzer) parseJVMBuild(path string, content []byte) {
+	var roots []string
+	if filepath.Base(path) == "pom.xml" {
+		for _, m := range mavenSourceDir.FindAllSubmatch(content, -1) {
+			dir := strings.TrimPrefix(strings.TrimPrefix(string(m[1]), "${project.basedir}/"), "${basedir}/")
+			roots = appendUnique(roots, filepath.Clean(dir))
+		}
+	} else {
+		for _, m := range gradleSrcDirs.FindAllSubmatch(content, -1) {
+			for _, q := range quoted.FindAllSubmatch(m[1], -1) {
+				roots = appendUnique(roots, filepath.Clean(string(q[1])))
+			}
+		}
+	}
+	for _, r := range defaultSourceRoots {
+		roots = appendUnique(roots, filepath.FromSlash(r))
+	}
+	a.jvmProjects[filepath.Dir(path)] = JVMProject{SourceRoots: roots}
+}
+
+// resolveJVMImport resolves a Java or Kotlin import against the source roots of the nearest Maven or Gradle project.
+// `com.acme.domain.Invoice` resolves to the file declaring the class, `com.acme.domain.*` and imports of
+// top-level Kotlin functions to the package directory. Static imports and nested classes resolve to the
+// file of their outermost class.
+// Roots of the importing project are tried first, then those of the other projects; when no analyzed file
+// matches, the import resolves to the source root of the importing file, unless no root holds its top-level
+// package (e.g. `com.acme`), in which case it is returned as written. Such guessed paths are resolved again
+// as the following files are analyzed, so the result does not depend on the scan order.
+func (a *Analyzer) resolveJVMImport(sourcePath, importStr string) string {
+	dir := filepath.Dir(sourcePath)
+	var project JVMProject
+	var found bool
+	for {
+		if p, ok := a.jvmProjects[dir]; ok {
+			project = p
+			found = true
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	if !found {
+		return importStr
+	}
+
+	segments := strings.Split(importStr, ".")
+	class := -1
+	for i, s := range segments {
+		if s != "" && unicode.IsUpper([]rune(s)[0]) {
+			class = i
+			break
+		}
+	}
+	var rel string
+	var extensions []string
+	if class >= 0 {
+		rel = filepath.Join(segments[:class+1]...)
+		extensions = jvmExtensions
+	} else {
+		rel = filepath.Join(segments[:len(segments)-1]...)
+		extensions = []string{""}
+	}
+
+	candidates := a.jvmRoots(dir, project)
+	for _, root := range candidates {
+		for _, ext := range extensions {
+			target := filepath.Join(root, rel) + ext
+			if a.jvmTargetExists(target, ext == "") {
+				return target
+			}
+		}
+	}
+
+	// Classes of unknown top-level packages, such as those of the JDK or of libraries, are external
+	if !a.jvmPackageKnown(candidates, segments) {
+		return importStr
+	}
+
+	// Not analyzed yet: assume it lives next to the importing file
+	root := filepath.Join(dir, project.SourceRoots[0])
+	for _, r := range candidates {
+		if strings.HasPrefix(sourcePath, r+string(filepath.Separator)) {
+			root = r
+			break
+		}
+	}
+	ext := ""
+	if class >= 0 {
+		ext = filepath.Ext(sourcePath)
+	}
+	target := filepath.Join(root, rel) + ext
+	if a.jvmPending[sourcePath] == nil {
+		a.jvmPending[sourcePath] = make(map[string]string)
+	}
+	a.jvmPending[sourcePath][importStr] = target
+	return target
+}
+
+// indexJVMFile records an analyzed Java or Kotlin file in its package and forgets its guessed imports,
+// which are resolved again. It reports whether the file is new.
+func (a *Analyzer) indexJVMFile(path string) bool {
+	delete(a.jvmPending, path)
+	dir := filepath.Dir(path)
+	if contains(a.jvmPackages[dir], path) {
+		return false
+	}
+	a.jvmPackages[dir] = append(a.jvmPackages[dir], path)
+	return true
+}
+
+// unindexJVMFile removes a Java or Kotlin file from its package.
+func (a *Analyzer) unindexJVMFile(path string) {
+	delete(a.jvmPending, path)
+	dir := filepath.Dir(path)
+	var kept []string
+	for _, f := range a.jvmPackages[dir] {
+		if f != path {
+			kept = append(kept, f)
+		}
+	}
+	if len(kept) == 0 {
+		delete(a.jvmPackages, dir)
+	} else {
+		a.jvmPackages[dir] = kept
+	}
+}
+
+// resolvePendingJVMImports resolves the guessed imports again, now that another file is analyzed,
+// and moves their IMPORTS edges and positions to the files they resolve to.
+func (a *Analyzer) resolvePendingJVMImports() {
+	sources := make([]string, 0, len(a.jvmPending))
+	for source := range a.jvmPending {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		pending := a.jvmPending[source]
+		delete(a.jvmPending, source)
+		var moved map[string]string
+		for importStr, guessed := range pending {
+			target := a.resolveJVMImport(source, importStr)
+			if target == guessed {
+				continue
+			}
+			a.Graph.RemoveEdge(source, guessed, domain.EdgeTypeImports)
+			a.Graph.AddEdge(source, target, domain.EdgeTypeImports)
+			if moved == nil {
+				moved = make(map[string]string)
+			}
+			moved[guessed] = target
+		}
+		if node, ok := a.Graph.GetNode(source); ok && moved != nil {
+			positions := make(map[string]interface{})
+			if previous, ok := node.Properties["import_positions"].(map[string]interface{}); ok {
+				for id, pos := range previous {
+					if target, ok := moved[id]; ok {
+						id = target
+					}
+					positions[id] = pos
+				}
+			}
+			a.Graph.AddNode(withProperties(node, map[string]interface{}{"import_positions": positions}))
+		}
+	}
+}
+
+// jvmPackageKnown reports whether a source root holds the top-level package of an import, i.e. its first two segments.
+func (a *Analyzer) jvmPackageKnown(roots, segments []string) bool {
+	n := 2
+	if len(segments)-1 < n {
+		n = len(segments) - 1
+	}
+	if n <= 0 {
+		return false
+	}
+	for _, root := range roots {
+		dir := filepath.Join(append([]string{root}, segments[:n]...)...)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return true
+		}
+		for pkg := range a.jvmPackages {
+			if isWithin(pkg, dir) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jvmRoots returns the absolute source roots of the project in dir, followed by those of the other known projects.
+func (a *Analyzer) jvmRoots(dir string, project JVMProject) []string {
+	var roots []string
+	for _, r := range project.SourceRoots {
+		roots = append(roots, filepath.Join(dir, r))
+	}
+	var others []string
+	for other := range a.jvmProjects {
+		if other != dir {
+			others = append(others, other)
+		}
+	}
+	sort.Strings(others)
+	for _, other := range others {
+		for _, r := range a.jvmProjects[other].SourceRoots {
+			roots = appendUnique(roots, filepath.Join(other, r))
+		}
+	}
+	return roots
+}
+
+// jvmTargetExists reports whether a code file, or for packages a directory of Java or Kotlin files, exists.
+func (a *Analyzer) jvmTargetExists(target string, pkg bool) bool {
+	if !pkg {
+		return a.isFile(target)
+	}
+	if len(a.jvmPackages[target]) > 0 {
+		return true
+	}
+	info, err := os.Stat(target)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,77 @@
+package analysis
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/config"
+)
+
+// layerMatcher is a compiled layer pattern from the configuration.
+type layerMatcher struct {
+	layer  string         // The layer assigned to matching paths.
+	source string         // Where the pattern comes from, recorded in node metadata for debugging.
+	re     *regexp.Regexp // The compiled pattern.
+}
+
+// compileLayerMatchers compiles the configured layer overrides and layer patterns, in matching order.
+// Overrides come first, sorted by pattern for deterministic results, followed by the layer patterns.
+// Invalid patterns are skipped; LoadConfig reports them when the configuration is read.
+func compileLayerMatchers(cfg *config.Config) []layerMatcher {
+	var matchers []layerMatcher
+
+	overrides := make([]string, 0, len(cfg.LayerOverrides))
+	for p := range cfg.LayerOverrides {
+		overrides = append(overrides, p)
+	}
+	sort.Strings(overrides)
+	for _, p := range overrides {
+		if re, err := config.CompilePattern(p); err == nil {
+			matchers = append(matchers, layerMatcher{layer: cfg.LayerOverrides[p], source: "override:" + p, re: re})
+		}
+	}
+
+	for _, l := range cfg.Layers {
+		for _, p := range l.Patterns {
+			if re, err := config.CompilePattern(p); err == nil {
+				matchers = append(matchers, layerMatcher{layer: l.Name, source: "pattern:" + p, re: re})
+			}
+		}
+	}
+
+	return matchers
+}
+
+// detectLayer infers the architectural layer of a path from the configured overrides and layer patterns.
+// It returns the layer and the pattern that matched it, or empty strings if the path is unlayered
+// or its layer is not part of the IncludedLayers.
+func (a *Analyzer) detectLayer(path string) (string, string) {
+	m, _ := a.matchLayer(path)
+	if m == nil || a.includedLayer(m.layer) == "" {
+		return "", ""
+	}
+	return m.layer, m.source
+}
+
+// matchLayer returns the first layer matcher that matches the path, along with its submatches.
+func (a *Analyzer) matchLayer(path string) (*layerMatcher, []string) {
+	slashed := filepath.ToSlash(path)
+	for i, m := range a.layerMatchers {
+		if sub := m.re.FindStringSubmatch(slashed); sub != nil {
+			return &a.layerMatchers[i], sub
+		}
+	}
+	return nil, nil
+}
+
+// includedLayer returns layer if it is listed in the configured IncludedLayers,
+// or an empty string so that the file is treated as unlayered.
+func (a *Analyzer) includedLayer(layer string) string {
+	for _, l := range a.Config.IncludedLayers {
+		if l == layer {
+			return layer
+		}
+	}
+	return ""
+}
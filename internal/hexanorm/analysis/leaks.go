@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+)
+
+// findLayerLeaks follows the import chains of every layered code node through unlayered files, up to the
+// configured maximum depth, and reports the layers its LayerRules forbid that they reach, with the shortest path.
+// Chains stop at layered files, whose own imports are checked directly, and at external packages.
+func (a *Analyzer) findLayerLeaks() []domain.Violation {
+	if !a.Config.Transitive.Enabled {
+		return nil
+	}
+	var violations []domain.Violation
+
+	type step struct {
+		id   string
+		path []string
+	}
+	for _, node := range a.filterNodes(domain.NodeKindCode) {
+		layer, _ := node.Metadata["layer"].(string)
+		if layer == "" {
+			continue
+		}
+
+		// Chains start at the unlayered files the node imports
+		visited := map[string]bool{node.ID: true}
+		var queue []step
+		for _, id := range a.importsOf(node.ID) {
+			if l, ok := a.importedLayer(id); ok && l == "" && !visited[id] {
+				visited[id] = true
+				queue = append(queue, step{id, []string{node.ID, id}})
+			}
+		}
+
+		for len(queue) > 0 {
+			s := queue[0]
+			queue = queue[1:]
+			if len(s.path) > a.Config.Transitive.MaxDepth {
+				continue
+			}
+			for _, id := range a.importsOf(s.id) {
+				targetLayer, ok := a.importedLayer(id)
+				if !ok || visited[id] {
+					continue
+				}
+				visited[id] = true
+				path := append(append([]string{}, s.path...), id)
+				if targetLayer == "" {
+					queue = append(queue, step{id, path})
+					continue
+				}
+				if allowed, severity := a.checkLayerRule(layer, targetLayer); !allowed {
+					violations = append(violations, atImport(domain.Violation{
+						Severity:    severity,
+						Message:     fmt.Sprintf("Layer Leak: '%s' (%s) reaches '%s' (%s) through %s.", node.ID, layer, id, targetLayer, strings.Join(path, " -> ")),
+						File:        node.ID,
+						Target:      strings.Join(path, " -> "),
+						Kind:        domain.ViolationKindLayerLeak,
+						RuleID:      "leak/" + layer,
+						Remediation: fmt.Sprintf("Move the %s dependency of '%s' behind a port, or give it a layer so that the layer rules apply to it directly.", targetLayer, s.id),
+					}, node, path[1]))
+				}
+			}
+		}
+	}
+
+	return violations
+}
+
+// importsOf returns the targets of the IMPORTS edges of a code node, or of the files a Go package contains.
+func (a *Analyzer) importsOf(id string) []string {
+	sources := []string{id}
+	if n, ok := a.Graph.GetNode(id); ok && n.Kind == domain.NodeKindPackage {
+		sources = nil
+		for _, edge := range a.Graph.GetEdgesFrom(id) {
+			if edge.Type == domain.EdgeTypeContains {
+				sources = append(sources, edge.TargetID)
+			}
+		}
+	}
+
+	var targets []string
+	for _, source := range sources {
+		for _, edge := range a.Graph.GetEdgesFrom(source) {
+			if edge.Type == domain.EdgeTypeImports {
+				targets = appendUnique(targets, edge.TargetID)
+			}
+		}
+	}
+	return targets
+}
+
+// importedLayer returns the layer of an import target, inferred from its path when it was not analyzed, as the
+// layer rules do. External packages have none and report false.
+func (a *Analyzer) importedLayer(id string) (string, bool) {
+	n, ok := a.Graph.GetNode(id)
+	if !ok {
+		layer, _ := a.detectLayer(id)
+		return layer, true
+	}
+	if n.Kind == domain.NodeKindExternal {
+		return "", false
+	}
+	layer, _ := n.Metadata["layer"].(string)
+	return layer, true
+}
@@ -0,0 +1,143 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/parser"
+)
+
+// Metrics holds Robert C. Martin's package metrics of the code, grouped by directory, bounded context and layer.
+type Metrics struct {
+	Directories []PackageMetrics `json:"directories"`
+	Contexts    []PackageMetrics `json:"contexts"`
+	Layers      []PackageMetrics `json:"layers"`
+}
+
+// PackageMetrics holds the coupling and stability metrics of a group of code files, computed from the IMPORTS
+// edges between analyzed files. Imports of external packages are not counted.
+type PackageMetrics struct {
+	Name             string  `json:"name"`              // The directory, relative to the RootDir, the context or the layer.
+	Files            int     `json:"files"`             // Number of code files in the group.
+	AfferentCoupling int     `json:"afferent_coupling"` // Ca: files outside the group importing a file of the group.
+	EfferentCoupling int     `json:"efferent_coupling"` // Ce: files of the group importing a file outside the group.
+	Instability      float64 `json:"instability"`       // I = Ce / (Ca + Ce), or 0 for an uncoupled group.
+	AbstractTypes    int     `json:"abstract_types"`    // Interfaces, traits and abstract classes declared in the group.
+	ConcreteTypes    int     `json:"concrete_types"`    // Classes and structs declared in the group.
+	Abstractness     float64 `json:"abstractness"`      // A = abstract types / types, or 0 for a group without types.
+	DistanceFromMain float64 `json:"distance"`          // D = |A + I - 1|, the distance from the main sequence.
+}
+
+// metricsGroup returns the name of the group a code node or Go package belongs to, or "" if it belongs to none.
+type metricsGroup func(n *domain.Node) string
+
+// CalculateMetrics computes the package metrics of every directory, bounded context and layer holding code files.
+// Groups are sorted by name.
+func (a *Analyzer) CalculateMetrics() Metrics {
+	directory := func(n *domain.Node) string { return a.relativePath(packageOf(n)) }
+	context := func(n *domain.Node) string { return stringProp(n.Metadata["context"]) }
+	layer := func(n *domain.Node) string { return stringProp(n.Metadata["layer"]) }
+
+	return Metrics{
+		Directories: a.packageMetrics(directory),
+		Contexts:    a.packageMetrics(context),
+		Layers:      a.packageMetrics(layer),
+	}
+}
+
+// packageMetrics computes the metrics of the groups of code files at one level. Files outside any group,
+// such as unlayered files at the layer level, are left out but still count as outside the other groups.
+func (a *Analyzer) packageMetrics(groupOf metricsGroup) []PackageMetrics {
+	groups := make(map[string]*PackageMetrics)
+	group := func(name string) *PackageMetrics {
+		if groups[name] == nil {
+			groups[name] = &PackageMetrics{Name: name}
+		}
+		return groups[name]
+	}
+	// The files importing into each group, and those of each group importing out of it
+	afferent := make(map[string]map[string]bool)
+	efferent := make(map[string]map[string]bool)
+	add := func(files map[string]map[string]bool, name, file string) {
+		group(name)
+		if files[name] == nil {
+			files[name] = make(map[string]bool)
+		}
+		files[name][file] = true
+	}
+
+	for _, node := range a.filterNodes(domain.NodeKindCode) {
+		src := groupOf(node)
+		if src != "" {
+			group(src).Files++
+		}
+		for _, edge := range a.Graph.GetEdgesFrom(node.ID) {
+			if edge.Type != domain.EdgeTypeImports {
+				continue
+			}
+			target, ok := a.Graph.GetNode(edge.TargetID)
+			if !ok || target.Kind != domain.NodeKindCode && target.Kind != domain.NodeKindPackage {
+				continue
+			}
+			dst := groupOf(target)
+			if dst == src {
+				continue
+			}
+			if src != "" {
+				add(efferent, src, node.ID)
+			}
+			if dst != "" {
+				add(afferent, dst, node.ID)
+			}
+		}
+	}
+
+	for _, sym := range a.filterNodes(domain.NodeKindSymbol) {
+		file, ok := a.Graph.GetNode(stringProp(sym.Properties["file"]))
+		if !ok {
+			continue
+		}
+		name := groupOf(file)
+		if name == "" {
+			continue
+		}
+		switch parser.SymbolKind(stringProp(sym.Properties["symbol_kind"])) {
+		case parser.SymbolKindInterface, parser.SymbolKindTrait, parser.SymbolKindAbstractClass:
+			group(name).AbstractTypes++
+		case parser.SymbolKindClass, parser.SymbolKindStruct:
+			group(name).ConcreteTypes++
+		}
+	}
+
+	res := make([]PackageMetrics, 0, len(groups))
+	for _, m := range groups {
+		m.AfferentCoupling, m.EfferentCoupling = len(afferent[m.Name]), len(efferent[m.Name])
+		if coupling := m.AfferentCoupling + m.EfferentCoupling; coupling > 0 {
+			m.Instability = ratio(m.EfferentCoupling, coupling)
+		}
+		if types := m.AbstractTypes + m.ConcreteTypes; types > 0 {
+			m.Abstractness = ratio(m.AbstractTypes, types)
+		}
+		m.DistanceFromMain = round2(math.Abs(m.Abstractness + m.Instability - 1))
+		res = append(res, *m)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// ratio returns n / total rounded to two decimals.
+func ratio(n, total int) float64 {
+	return round2(float64(n) / float64(total))
+}
+
+// round2 rounds a metric to two decimals.
+func round2(x float64) float64 {
+	return math.Round(x*100) / 100
+}
+
+// stringProp returns a string property, or "" if it is missing.
+func stringProp(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
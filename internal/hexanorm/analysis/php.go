@@ -0,0 +1,122 @@
+package analysis
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ComposerProject represents the PSR-4 autoload map of a composer.json: namespace prefixes, with their trailing
+// backslash, to the directories holding their classes, relative to the directory of the composer.json.
+// `autoload` and `autoload-dev` are merged.
+type ComposerProject struct {
+	PSR4 map[string][]string
+}
+
+// composerJSON is the part of a composer.json read for resolution.
+type composerJSON struct {
+	Autoload    composerAutoload `json:"autoload"`
+	AutoloadDev composerAutoload `json:"autoload-dev"`
+}
+
+type composerAutoload struct {
+	PSR4 map[string]json.RawMessage `json:"psr-4"`
+}
+
+// parseComposer records the PSR-4 autoload map of a composer.json. Each prefix maps to a directory or a list of them.
+func (a *Analyzer) parseComposer(path string, content []byte) {
+	var composer composerJSON
+	if err := json.Unmarshal(content, &composer); err != nil {
+		return
+	}
+	project := ComposerProject{PSR4: make(map[string][]string)}
+	for _, autoload := range []composerAutoload{composer.Autoload, composer.AutoloadDev} {
+		for prefix, raw := range autoload.PSR4 {
+			var dirs []string
+			var dir string
+			if err := json.Unmarshal(raw, &dir); err == nil {
+				dirs = []string{dir}
+			} else if err := json.Unmarshal(raw, &dirs); err != nil {
+				continue
+			}
+			prefix = strings.TrimPrefix(prefix, "\\")
+			for _, d := range dirs {
+				project.PSR4[prefix] = appendUnique(project.PSR4[prefix], filepath.Clean(filepath.FromSlash(d)))
+			}
+		}
+	}
+	a.composerProjects[filepath.Dir(path)] = project
+}
+
+// resolvePHPImport resolves the fully qualified name of a PHP `use` statement to the file declaring it following PSR-4:
+// the longest matching namespace prefix is replaced by its directory and the rest of the name becomes the path,
+// so `App\Domain\User\User` with `"App\\": "src/"` resolves to `src/Domain/User/User.php`.
+// The autoload map of the nearest composer.json is tried first, then those of the other projects. When no
+// directory of the prefix holds the file, the name resolves to the first one. Names matching no prefix,
+// such as vendor classes, are returned as written.
+func (a *Analyzer) resolvePHPImport(sourcePath, importStr string) string {
+	name := strings.TrimPrefix(importStr, "\\")
+	var fallback string
+	for _, dir := range a.composerDirs(sourcePath) {
+		psr4 := a.composerProjects[dir].PSR4
+		prefixes := make([]string, 0, len(psr4))
+		for prefix := range psr4 {
+			if strings.HasPrefix(name, prefix) {
+				prefixes = append(prefixes, prefix)
+			}
+		}
+		// Longest prefix first
+		sort.Slice(prefixes, func(i, j int) bool {
+			if len(prefixes[i]) != len(prefixes[j]) {
+				return len(prefixes[i]) > len(prefixes[j])
+			}
+			return prefixes[i] < prefixes[j]
+		})
+		for _, prefix := range prefixes {
+			rel := filepath.FromSlash(strings.ReplaceAll(strings.TrimPrefix(name, prefix), "\\", "/")) + ".php"
+			for _, d := range psr4[prefix] {
+				target := filepath.Join(dir, d, rel)
+				if a.isFile(target) {
+					return target
+				}
+				if fallback == "" {
+					fallback = target
+				}
+			}
+		}
+	}
+	if fallback != "" {
+		return fallback
+	}
+	return importStr
+}
+
+// composerDirs returns the directory of the nearest composer.json above the file, followed by those of the other projects.
+func (a *Analyzer) composerDirs(sourcePath string) []string {
+	var nearest string
+	dir := filepath.Dir(sourcePath)
+	for {
+		if _, ok := a.composerProjects[dir]; ok {
+			nearest = dir
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	var others []string
+	for d := range a.composerProjects {
+		if d != nearest {
+			others = append(others, d)
+		}
+	}
+	sort.Strings(others)
+	if nearest == "" {
+		return others
+	}
+	return append([]string{nearest}, others...)
+}
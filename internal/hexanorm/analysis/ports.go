@@ -0,0 +1,284 @@
+package analysis
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/parser"
+)
+
+// analyzePortsAndAdapters adds Port and Adapter nodes for the types declared in a code file,
+// and links them with IMPLEMENTS edges to the adapters and ports already in the graph.
+// The ports and adapters of the previous version of the file are dropped first, with their edges,
+// and the IDs of the new ones are recorded on the file node, whose updated version is returned.
+func (a *Analyzer) analyzePortsAndAdapters(file *domain.Node, content []byte, lang parser.Language, previous []string) *domain.Node {
+	path := file.ID
+	layer, _ := file.Metadata["layer"].(string)
+	context, _ := file.Metadata["context"].(string)
+	hadPorts := a.dropPortsAndAdapters(path, previous)
+
+	types, _ := parser.ParseTypeDeclarations(content, lang)
+	declaresPorts := contains(a.Config.Ports.PortLayers, layer) || matchesAny(a.portPatterns, path)
+	declaresAdapters := contains(a.Config.Ports.AdapterLayers, layer)
+
+	var ids []string
+	var ports, adapters, candidates []*domain.Node
+	for _, t := range types {
+		node := &domain.Node{
+			Properties: map[string]interface{}{
+				"name":       t.Name,
+				"file":       path,
+				"start_line": t.Line,
+				"end_line":   t.EndLine,
+				"language":   string(lang),
+				"methods":    t.Methods,
+				"implements": t.Implements,
+			},
+			Metadata: map[string]interface{}{
+				"layer":   layer,
+				"context": context,
+			},
+		}
+		if t.IsAbstract() {
+			if !declaresPorts {
+				continue
+			}
+			node.ID = "port:" + path + "#" + t.Name
+			node.Kind = domain.NodeKindPort
+			ports = append(ports, node)
+		} else {
+			if !declaresAdapters || len(t.Methods) == 0 {
+				continue
+			}
+			node.ID = "adapter:" + path + "#" + t.Name
+			node.Kind = domain.NodeKindAdapter
+			// Types naming no supertype, such as DTOs and mappers, are adapters only in the adapter paths,
+			// or for Go, while they implement a port
+			if len(t.Implements) == 0 && !matchesAny(a.adapterPatterns, path) {
+				if lang == parser.LangGo {
+					candidates = append(candidates, node)
+				}
+				continue
+			}
+			adapters = append(adapters, node)
+		}
+		a.Graph.AddNode(node)
+		ids = append(ids, node.ID)
+	}
+
+	for _, adapter := range adapters {
+		for _, port := range a.filterNodes(domain.NodeKindPort) {
+			a.linkAdapter(adapter, port)
+		}
+	}
+	for _, port := range ports {
+		for _, adapter := range a.filterNodes(domain.NodeKindAdapter) {
+			a.linkAdapter(adapter, port)
+		}
+	}
+
+	if len(candidates) > 0 {
+		a.adapterCandidates[path] = candidates
+	}
+	if hadPorts || len(ports) > 0 {
+		// The structural adapters of every file may implement other ports now
+		a.linkStructuralAdapters("")
+	} else {
+		a.linkStructuralAdapters(path)
+	}
+
+	if len(ids) > 0 || len(previous) > 0 {
+		file = withProperties(file, map[string]interface{}{"ports_and_adapters": ids})
+		a.Graph.AddNode(file)
+	}
+	return file
+}
+
+// portsAndAdaptersOf returns the IDs of the Port and Adapter nodes declared in a file, except its structural adapters.
+func (a *Analyzer) portsAndAdaptersOf(path string) []string {
+	if n, ok := a.Graph.GetNode(path); ok {
+		return stringSlice(n.Properties["ports_and_adapters"])
+	}
+	return nil
+}
+
+// dropPortsAndAdapters removes the ports and adapters of a file, including its structural adapters,
+// with their IMPLEMENTS edges. It reports whether the file declared ports.
+func (a *Analyzer) dropPortsAndAdapters(path string, ids []string) bool {
+	hadPorts := false
+	for _, id := range ids {
+		if n, ok := a.Graph.GetNode(id); ok && n.Kind == domain.NodeKindPort {
+			hadPorts = true
+		}
+		a.Graph.RemoveNode(id)
+	}
+	for _, candidate := range a.adapterCandidates[path] {
+		a.Graph.RemoveNode(candidate.ID)
+	}
+	delete(a.adapterCandidates, path)
+	return hadPorts
+}
+
+// linkStructuralAdapters adds the Go types of the adapter layers that declare every method of a port as adapters,
+// linked to the ports they implement, and removes those that implement none anymore.
+// Only the types of the file are checked, or those of every file if path is "".
+func (a *Analyzer) linkStructuralAdapters(path string) {
+	candidates := a.adapterCandidates[path]
+	if path == "" {
+		for _, c := range a.adapterCandidates {
+			candidates = append(candidates, c...)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	ports := a.filterNodes(domain.NodeKindPort)
+	for _, candidate := range candidates {
+		var implemented []string
+		for _, port := range ports {
+			if implementsPort(candidate, port) {
+				implemented = append(implemented, port.ID)
+			}
+		}
+		if len(implemented) == 0 {
+			a.Graph.RemoveNode(candidate.ID)
+			continue
+		}
+		a.Graph.AddNode(candidate)
+		for _, id := range implemented {
+			a.Graph.AddEdge(candidate.ID, id, domain.EdgeTypeImplements)
+		}
+	}
+}
+
+// linkAdapter adds an IMPLEMENTS edge if the adapter implements the port.
+func (a *Analyzer) linkAdapter(adapter, port *domain.Node) {
+	if implementsPort(adapter, port) {
+		a.Graph.AddEdge(adapter.ID, port.ID, domain.EdgeTypeImplements)
+	}
+}
+
+// implementsPort reports whether the adapter implements the port.
+// Adapters implement a port when they name it explicitly (implements, extends, impl ... for),
+// or, for Go's structural typing, when they declare every method of the port.
+func implementsPort(adapter, port *domain.Node) bool {
+	portName, _ := port.Properties["name"].(string)
+	if contains(stringSlice(adapter.Properties["implements"]), portName) {
+		return true
+	}
+	if port.Properties["language"] != string(parser.LangGo) || adapter.Properties["language"] != string(parser.LangGo) {
+		return false
+	}
+
+	portMethods := stringSlice(port.Properties["methods"])
+	adapterMethods := stringSlice(adapter.Properties["methods"])
+	for _, m := range portMethods {
+		if !contains(adapterMethods, m) {
+			return false
+		}
+	}
+	return len(portMethods) > 0
+}
+
+// findPortViolations reports ports without adapters, adapters without ports,
+// and consumer code that imports concrete adapters instead of their ports.
+func (a *Analyzer) findPortViolations() []domain.Violation {
+	var violations []domain.Violation
+	severity := a.Config.Ports.Severity
+
+	for _, port := range a.filterNodes(domain.NodeKindPort) {
+		if !hasEdgeOfType(a.Graph.GetEdgesTo(port.ID), domain.EdgeTypeImplements) {
+			violations = append(violations, domain.Violation{
+				Severity:    severity,
+				Message:     fmt.Sprintf("Port Without Adapter: '%s' declared in '%s' has no adapter implementing it.", port.Properties["name"], port.Properties["file"]),
+				File:        port.Properties["file"].(string),
+				Target:      port.ID,
+				Kind:        domain.ViolationKindPortWithoutAdapter,
+				Line:        intProp(port.Properties["start_line"]),
+				EndLine:     intProp(port.Properties["end_line"]),
+				RuleID:      "ports/port-without-adapter",
+				Remediation: fmt.Sprintf("Implement '%s' with an adapter in an adapter layer, or remove the unused port.", port.Properties["name"]),
+			})
+		}
+	}
+
+	// Index adapters by file, and by package for Go whose imports point at directories.
+	adaptersByTarget := make(map[string][]string)
+	for _, adapter := range a.filterNodes(domain.NodeKindAdapter) {
+		name, _ := adapter.Properties["name"].(string)
+		file, _ := adapter.Properties["file"].(string)
+		adaptersByTarget[file] = append(adaptersByTarget[file], name)
+		if adapter.Properties["language"] == string(parser.LangGo) {
+			adaptersByTarget[filepath.Dir(file)] = append(adaptersByTarget[filepath.Dir(file)], name)
+		}
+
+		if !hasEdgeOfType(a.Graph.GetEdgesFrom(adapter.ID), domain.EdgeTypeImplements) {
+			violations = append(violations, domain.Violation{
+				Severity:    severity,
+				Message:     fmt.Sprintf("Adapter Without Port: '%s' declared in '%s' implements no port.", name, file),
+				File:        file,
+				Target:      adapter.ID,
+				Kind:        domain.ViolationKindAdapterWithoutPort,
+				Line:        intProp(adapter.Properties["start_line"]),
+				EndLine:     intProp(adapter.Properties["end_line"]),
+				RuleID:      "ports/adapter-without-port",
+				Remediation: fmt.Sprintf("Declare the port '%s' implements in the domain or application layer.", name),
+			})
+		}
+	}
+
+	for _, node := range a.filterNodes(domain.NodeKindCode) {
+		layer, _ := node.Metadata["layer"].(string)
+		if !contains(a.Config.Ports.ConsumerLayers, layer) {
+			continue
+		}
+		for _, edge := range a.Graph.GetEdgesFrom(node.ID) {
+			if edge.Type != domain.EdgeTypeImports {
+				continue
+			}
+			for _, name := range adaptersByTarget[edge.TargetID] {
+				violations = append(violations, atImport(domain.Violation{
+					Severity:    severity,
+					Message:     fmt.Sprintf("Adapter Used Directly: '%s' (%s) imports adapter '%s' from '%s'. Depend on its port instead.", node.ID, layer, name, edge.TargetID),
+					File:        node.ID,
+					Target:      SymbolID(edge.TargetID, name),
+					Kind:        domain.ViolationKindAdapterDirectUse,
+					RuleID:      "ports/adapter-direct-use",
+					Remediation: fmt.Sprintf("Depend on the port implemented by '%s' and inject the adapter.", name),
+				}, node, edge.TargetID))
+			}
+		}
+	}
+
+	return violations
+}
+
+// hasEdgeOfType reports whether any of the edges has the given type.
+func hasEdgeOfType(edges []*domain.Edge, edgeType domain.EdgeType) bool {
+	for _, e := range edges {
+		if e.Type == edgeType {
+			return true
+		}
+	}
+	return false
+}
+
+// stringSlice converts a node property holding a list of strings into a []string.
+// Properties loaded back from the store are decoded as []interface{}.
+func stringSlice(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		res := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package analysis
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// PythonProject represents the source roots of a Python project, relative to its directory.
+type PythonProject struct {
+	SourceRoots []string
+}
+
+// defaultPythonRoots are always tried after the declared source roots: the src-layout and the flat layout.
+var defaultPythonRoots = []string{"src", "."}
+
+var (
+	// pyproject.toml: setuptools `where = ["src"]`, pytest `pythonpath = ["src"]`, hatch `packages = ["src/app"]`
+	pyprojectRootLists = regexp.MustCompile(`(?m)^\s*(where|pythonpath|packages)\s*=\s*\[([^\]]*)\]`)
+	// pyproject.toml: setuptools `package-dir = {"" = "src"}`, poetry `{ include = "app", from = "src" }`
+	pyprojectRootDirs = regexp.MustCompile(`(?:package-dir\s*=\s*\{\s*""\s*=|\bfrom\s*=)\s*"([^"]+)"`)
+	// setup.cfg: `package_dir =\n    =src` and `where = src`
+	setupCfgRoots = regexp.MustCompile(`(?m)^\s*(?:package_dir\s*=\s*\n?\s*=|where\s*=)\s*(\S+)\s*$`)
+)
+
+// isPythonProjectFile reports whether the file declares a Python project.
+func isPythonProjectFile(path string) bool {
+	base := filepath.Base(path)
+	return base == "pyproject.toml" || base == "setup.cfg"
+}
+
+// parsePythonProject records the source roots declared in a `pyproject.toml` (setuptools, poetry, hatch, pytest)
+// or `setup.cfg`, followed by the src-layout and flat-layout defaults.
+func (a *Analyzer) parsePythonProject(path string, content []byte) {
+	var roots []string
+	if filepath.Base(path) == "setup.cfg" {
+		for _, m := range setupCfgRoots.FindAllSubmatch(content, -1) {
+			roots = appendUnique(roots, filepath.Clean(string(m[1])))
+		}
+	} else {
+		for _, m := range pyprojectRootLists.FindAllSubmatch(content, -1) {
+			for _, q := range quoted.FindAllSubmatch(m[2], -1) {
+				root := filepath.Clean(string(q[1]))
+				if string(m[1]) == "packages" {
+					// hatch lists package directories, whose parent is the root
+					if !strings.Contains(string(q[1]), "/") {
+						continue
+					}
+					root = filepath.Dir(root)
+				}
+				roots = appendUnique(roots, root)
+			}
+		}
+		for _, m := range pyprojectRootDirs.FindAllSubmatch(content, -1) {
+			roots = appendUnique(roots, filepath.Clean(string(m[1])))
+		}
+	}
+	for _, r := range defaultPythonRoots {
+		roots = appendUnique(roots, r)
+	}
+	dir := filepath.Dir(path)
+	if existing, ok := a.pythonProjects[dir]; ok {
+		// pyproject.toml and setup.cfg side by side
+		for _, r := range roots {
+			existing.SourceRoots = appendUnique(existing.SourceRoots, r)
+		}
+		roots = existing.SourceRoots
+	}
+	a.pythonProjects[dir] = PythonProject{SourceRoots: roots}
+}
+
+// resolvePythonImport resolves a Python import to the module file or the `__init__.py` of the package it loads.
+// Relative imports (`.models`, `..user`) are resolved from the importing file's package. Absolute imports are
+// resolved against the source roots of the nearest Python project, or the directories above the file when
+// there is none. An imported name that is not a submodule, e.g. `User` in `app.domain.user.User`,
+// resolves to the module declaring it.
+func (a *Analyzer) resolvePythonImport(sourcePath, importStr string) string {
+	if strings.HasPrefix(importStr, ".") {
+		rest := strings.TrimLeft(importStr, ".")
+		base := filepath.Dir(sourcePath)
+		for i := 1; i < len(importStr)-len(rest); i++ {
+			base = filepath.Dir(base)
+		}
+		segments := pythonSegments(rest)
+		if resolved := a.probePythonModule([]string{base}, segments); resolved != "" {
+			return resolved
+		}
+		if len(segments) > 0 {
+			if resolved := a.probePythonModule([]string{base}, segments[:len(segments)-1]); resolved != "" {
+				return resolved
+			}
+		}
+		return filepath.Join(append([]string{base}, segments...)...)
+	}
+
+	roots := a.pythonRoots(sourcePath)
+	segments := pythonSegments(importStr)
+	if resolved := a.probePythonModule(roots, segments); resolved != "" {
+		return resolved
+	}
+	if len(segments) > 1 {
+		if resolved := a.probePythonModule(roots, segments[:len(segments)-1]); resolved != "" {
+			return resolved
+		}
+	}
+	return importStr
+}
+
+// pythonRoots returns the absolute source roots of the nearest Python project, or the directories above the file.
+func (a *Analyzer) pythonRoots(sourcePath string) []string {
+	var ancestors []string
+	dir := filepath.Dir(sourcePath)
+	for {
+		if p, ok := a.pythonProjects[dir]; ok {
+			var roots []string
+			for _, r := range p.SourceRoots {
+				roots = append(roots, filepath.Join(dir, r))
+			}
+			return roots
+		}
+		ancestors = append(ancestors, dir)
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ancestors
+		}
+		dir = parent
+	}
+}
+
+// probePythonModule returns the module file (`a/b.py`) or package init (`a/b/__init__.py`) of the dotted name
+// in the first root holding it, or "" if none does.
+func (a *Analyzer) probePythonModule(roots []string, segments []string) string {
+	for _, root := range roots {
+		path := filepath.Join(append([]string{root}, segments...)...)
+		if len(segments) > 0 && a.isFile(path+".py") {
+			return path + ".py"
+		}
+		if init := filepath.Join(path, "__init__.py"); a.isFile(init) {
+			return init
+		}
+	}
+	return ""
+}
+
+func pythonSegments(dotted string) []string {
+	if dotted == "" {
+		return nil
+	}
+	return strings.Split(dotted, ".")
+}
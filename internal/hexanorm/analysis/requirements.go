@@ -0,0 +1,146 @@
+package analysis
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/parser"
+)
+
+// isRequirementFile reports whether the file is a Markdown or YAML file under a requirements directory.
+func (a *Analyzer) isRequirementFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown", ".yaml", ".yml":
+		return matchesAny(a.requirementDirs, path)
+	}
+	return false
+}
+
+// analyzeRequirement turns a requirement file into a Requirement node, with a Feature node
+// and a DEFINES edge for each feature it lists.
+func (a *Analyzer) analyzeRequirement(path string, content []byte) error {
+	ext := strings.ToLower(filepath.Ext(path))
+	doc, err := parser.ParseRequirement(content, ext == ".md" || ext == ".markdown")
+	if err != nil {
+		return err
+	}
+
+	// The file may have declared another requirement before
+	for _, n := range a.requirementsDeclaredIn(path) {
+		if doc == nil || n.ID != doc.ID {
+			a.Graph.RemoveNode(n.ID)
+		}
+	}
+	if doc == nil {
+		return nil
+	}
+
+	props := propertiesOf(domain.RequirementProps{
+		Title:              doc.Title,
+		Description:        doc.Description,
+		Status:             doc.Status,
+		Priority:           doc.Priority,
+		ExternalLink:       doc.ExternalLink,
+		AcceptanceCriteria: doc.AcceptanceCriteria,
+	})
+	props["file"] = path
+	a.Graph.AddNode(&domain.Node{
+		ID:         doc.ID,
+		Kind:       domain.NodeKindRequirement,
+		Properties: props,
+	})
+
+	a.Graph.RemoveEdgesFrom(doc.ID, domain.EdgeTypeDefines)
+	for _, name := range doc.Features {
+		featID := "feature:" + name
+		if _, exists := a.Graph.GetNode(featID); !exists {
+			a.Graph.AddNode(&domain.Node{
+				ID:         featID,
+				Kind:       domain.NodeKindFeature,
+				Properties: map[string]interface{}{"name": name},
+			})
+		}
+		a.Graph.AddEdge(doc.ID, featID, domain.EdgeTypeDefines)
+	}
+	return nil
+}
+
+// requirementsDeclaredIn returns the Requirement nodes declared by a requirement file.
+func (a *Analyzer) requirementsDeclaredIn(path string) []*domain.Node {
+	var res []*domain.Node
+	for _, n := range a.filterNodes(domain.NodeKindRequirement) {
+		if n.Properties["file"] == path {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
+// propertiesOf converts a properties struct into a node properties map using its JSON field names.
+func propertiesOf(v interface{}) map[string]interface{} {
+	props := make(map[string]interface{})
+	data, err := json.Marshal(v)
+	if err == nil {
+		json.Unmarshal(data, &props)
+	}
+	return props
+}
+
+// requirementIDs returns the IDs of the requirements referenced by Gherkin tags matching the requirement tag pattern.
+func (a *Analyzer) requirementIDs(tags []string) []string {
+	if a.requirementTag == nil {
+		return nil
+	}
+	var ids []string
+	for _, tag := range tags {
+		m := a.requirementTag.FindStringSubmatch(tag)
+		switch {
+		case m == nil:
+			continue
+		case len(m) > 1 && m[1] != "":
+			ids = appendUnique(ids, m[1])
+		default:
+			ids = appendUnique(ids, strings.TrimPrefix(tag, "@"))
+		}
+	}
+	return ids
+}
+
+// ensureRequirement adds a Requirement node with the given title unless it already exists, e.g. from a requirement file.
+func (a *Analyzer) ensureRequirement(id, title string) {
+	if _, exists := a.Graph.GetNode(id); exists {
+		return
+	}
+	a.Graph.AddNode(&domain.Node{
+		ID:         id,
+		Kind:       domain.NodeKindRequirement,
+		Properties: map[string]interface{}{"title": title},
+	})
+}
+
+// linkFeatureRequirements links the requirements tagged on a Gherkin feature with DESCRIBED_BY edges,
+// removing the links of tags that were removed.
+func (a *Analyzer) linkFeatureRequirements(featID string, tags []string) {
+	ids := a.requirementIDs(tags)
+	for _, edge := range a.Graph.GetEdgesTo(featID) {
+		if edge.Type == domain.EdgeTypeDescribedBy && !contains(ids, edge.SourceID) {
+			a.Graph.RemoveEdge(edge.SourceID, featID, domain.EdgeTypeDescribedBy)
+		}
+	}
+	for _, id := range ids {
+		a.ensureRequirement(id, "Requirement referenced by tag @"+id)
+		a.Graph.AddEdge(id, featID, domain.EdgeTypeDescribedBy)
+	}
+}
+
+// linkScenarioRequirements links a Gherkin scenario to the requirements it is tagged with, including the tags
+// inherited from its feature and rule, with VERIFIES edges.
+func (a *Analyzer) linkScenarioRequirements(scID string, tags []string) {
+	a.Graph.RemoveEdgesFrom(scID, domain.EdgeTypeVerifies)
+	for _, id := range a.requirementIDs(tags) {
+		a.ensureRequirement(id, "Requirement referenced by tag @"+id)
+		a.Graph.AddEdge(scID, id, domain.EdgeTypeVerifies)
+	}
+}
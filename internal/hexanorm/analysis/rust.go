@@ -0,0 +1,210 @@
+package analysis
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Crate represents a Rust package declared by a Cargo.toml.
+type Crate struct {
+	Name string            // The crate name as used in paths, e.g. `hexa_domain` for the package `hexa-domain`.
+	Dir  string            // Directory of the Cargo.toml.
+	Deps map[string]string // Path dependencies by the name they are used under, to the directory of their Cargo.toml.
+}
+
+var (
+	cargoSection   = regexp.MustCompile(`^\[([^\]]+)\]`)
+	cargoString    = regexp.MustCompile(`^([\w-]+)\s*=\s*"([^"]*)"`)
+	cargoInlineDep = regexp.MustCompile(`^([\w-]+)\s*=\s*\{(.*)\}`)
+	cargoInlineKey = regexp.MustCompile(`\b(path|package)\s*=\s*"([^"]*)"`)
+)
+
+// parseCargo records the crate declared by a Cargo.toml: its `[package]` (or `[lib]`) name and its path dependencies,
+// possibly renamed with `package = "..."`. Workspace manifests without a package only declare members,
+// which have their own Cargo.toml.
+func (a *Analyzer) parseCargo(path string, content []byte) {
+	dir := filepath.Dir(path)
+	crate := Crate{Dir: dir, Deps: make(map[string]string)}
+	var libName, section, depName string
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if m := cargoSection.FindStringSubmatch(line); m != nil {
+			section, depName = m[1], ""
+			// [dependencies.name] tables declare one dependency
+			for _, deps := range []string{"dependencies.", "dev-dependencies.", "build-dependencies."} {
+				if strings.HasPrefix(section, deps) {
+					section, depName = strings.TrimSuffix(deps, "."), strings.TrimPrefix(section, deps)
+				}
+			}
+			continue
+		}
+		switch {
+		case section == "package" || section == "lib":
+			if m := cargoString.FindStringSubmatch(line); m != nil && m[1] == "name" {
+				if section == "lib" {
+					libName = m[2]
+				} else {
+					crate.Name = m[2]
+				}
+			}
+		case strings.HasSuffix(section, "dependencies") && depName != "":
+			if m := cargoString.FindStringSubmatch(line); m != nil && m[1] == "path" {
+				crate.Deps[rustIdent(depName)] = filepath.Join(dir, m[2])
+			}
+		case strings.HasSuffix(section, "dependencies"):
+			if m := cargoInlineDep.FindStringSubmatch(line); m != nil {
+				for _, kv := range cargoInlineKey.FindAllStringSubmatch(m[2], -1) {
+					if kv[1] == "path" {
+						crate.Deps[rustIdent(m[1])] = filepath.Join(dir, kv[2])
+					}
+				}
+			}
+		}
+	}
+	if libName != "" {
+		crate.Name = libName
+	}
+	crate.Name = rustIdent(crate.Name)
+	a.crates[dir] = crate
+}
+
+// rustIdent returns the identifier a crate is referenced by in paths.
+func rustIdent(name string) string {
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// resolveRustImport resolves a `use` path, or a `mod` declaration as `self::name`, to the file of the module
+// it names, following the module tree: `a::b` is `a.rs` or `a/mod.rs`, then `a/b.rs` or `a/b/mod.rs`.
+// Paths start from the crate root (`crate::`), the current module (`self::`), its ancestors (`super::`),
+// a workspace or path-dependency crate by name, or a child module of the current one.
+// Items such as types resolve to the file of the module declaring them. Paths of external crates are returned as written.
+func (a *Analyzer) resolveRustImport(sourcePath, importStr string) string {
+	crate, ok := a.nearestCrate(sourcePath)
+	if !ok {
+		return importStr
+	}
+	rootDir := rustRootDir(sourcePath, crate)
+	segments := strings.Split(importStr, "::")
+
+	var dir string
+	relative := false
+	switch segments[0] {
+	case "crate":
+		dir, segments = rootDir, segments[1:]
+	case "self":
+		dir, segments = rustModuleDir(sourcePath, rootDir), segments[1:]
+	case "super":
+		dir = rustModuleDir(sourcePath, rootDir)
+		for len(segments) > 0 && segments[0] == "super" {
+			dir, segments = filepath.Dir(dir), segments[1:]
+		}
+	default:
+		if depDir, ok := a.crateDir(crate, segments[0]); ok {
+			rootDir = filepath.Join(depDir, "src")
+			dir, segments = rootDir, segments[1:]
+		} else {
+			// A child module of the current one (2018 edition uniform paths), or an external crate
+			dir, relative = rustModuleDir(sourcePath, rootDir), true
+		}
+	}
+
+	current := a.rustModuleFile(dir, rootDir, sourcePath)
+	resolved := false
+	for _, seg := range segments {
+		next := ""
+		for _, candidate := range []string{filepath.Join(dir, seg+".rs"), filepath.Join(dir, seg, "mod.rs")} {
+			if a.isFile(candidate) {
+				next = candidate
+				break
+			}
+		}
+		if next == "" {
+			break
+		}
+		current, dir, resolved = next, filepath.Join(dir, seg), true
+	}
+
+	if current == "" || relative && !resolved {
+		return importStr
+	}
+	return current
+}
+
+// nearestCrate returns the crate of the closest Cargo.toml declaring a package above the file.
+func (a *Analyzer) nearestCrate(sourcePath string) (Crate, bool) {
+	dir := filepath.Dir(sourcePath)
+	for {
+		if c, ok := a.crates[dir]; ok && c.Name != "" {
+			return c, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return Crate{}, false
+		}
+		dir = parent
+	}
+}
+
+// crateDir returns the directory of the crate a path starts with: a path dependency of the importing crate,
+// or any crate of the workspace by name.
+func (a *Analyzer) crateDir(from Crate, name string) (string, bool) {
+	if dir, ok := from.Deps[name]; ok {
+		return dir, true
+	}
+	dirs := make([]string, 0, len(a.crates))
+	for dir := range a.crates {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		if a.crates[dir].Name == name {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+// rustRootDir returns the directory of the crate root of the file: `src/` for the library and main binary,
+// or the directory of a standalone crate root such as `src/bin/tool.rs` or `tests/api.rs`.
+func rustRootDir(sourcePath string, crate Crate) string {
+	src := filepath.Join(crate.Dir, "src")
+	if strings.HasPrefix(sourcePath, src+string(filepath.Separator)) && filepath.Dir(sourcePath) != filepath.Join(src, "bin") {
+		return src
+	}
+	return filepath.Dir(sourcePath)
+}
+
+// rustModuleDir returns the directory holding the files of the child modules of the file's module:
+// the file's directory for crate roots and `mod.rs` files, `dir/name/` for `dir/name.rs`.
+func rustModuleDir(sourcePath, rootDir string) string {
+	dir, base := filepath.Dir(sourcePath), filepath.Base(sourcePath)
+	if base == "mod.rs" || dir == rootDir && (base == "lib.rs" || base == "main.rs" || rootDir != filepath.Join(filepath.Dir(rootDir), "src")) {
+		return dir
+	}
+	return filepath.Join(dir, strings.TrimSuffix(base, ".rs"))
+}
+
+// rustModuleFile returns the file of the module whose children live in dir, or "" if it is unknown.
+func (a *Analyzer) rustModuleFile(dir, rootDir, sourcePath string) string {
+	var candidates []string
+	if dir == rootDir {
+		candidates = []string{filepath.Join(dir, "lib.rs"), filepath.Join(dir, "main.rs")}
+		if filepath.Dir(sourcePath) == rootDir {
+			candidates = append(candidates, sourcePath)
+		}
+	} else {
+		candidates = []string{dir + ".rs", filepath.Join(dir, "mod.rs")}
+	}
+	for _, c := range candidates {
+		if a.isFile(c) {
+			return c
+		}
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package analysis
+
+import (
+	"path/filepath"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/parser"
+)
+
+// SymbolID returns the node ID of a symbol declared in a file, e.g. `src/domain/vat.ts#VatService.calculate`.
+func SymbolID(path, qualifiedName string) string {
+	return path + "#" + qualifiedName
+}
+
+// analyzeSymbols adds a Symbol node for every type, function and method declared in a code file.
+// Types and functions are linked from the file with CONTAINS edges, methods from their type
+// when it is declared in the same file. Symbols that were removed from the file are pruned.
+func (a *Analyzer) analyzeSymbols(path string, content []byte, lang parser.Language, layer, context string) {
+	symbols, err := parser.ParseSymbols(content, lang)
+	if err != nil {
+		return
+	}
+
+	types := make(map[string]bool)
+	for _, s := range symbols {
+		if s.Parent == "" && s.Kind != parser.SymbolKindFunction {
+			types[s.Name] = true
+		}
+	}
+
+	current := make(map[string]bool)
+	for _, s := range symbols {
+		id := SymbolID(path, s.QualifiedName())
+		current[id] = true
+		a.Graph.AddNode(&domain.Node{
+			ID:   id,
+			Kind: domain.NodeKindSymbol,
+			Properties: map[string]interface{}{
+				"name":           s.Name,
+				"qualified_name": s.QualifiedName(),
+				"symbol_kind":    string(s.Kind),
+				"file":           path,
+				"start_line":     s.StartLine,
+				"end_line":       s.EndLine,
+			},
+			Metadata: map[string]interface{}{
+				"layer":    layer,
+				"context":  context,
+				"language": string(lang),
+			},
+		})
+
+		container := path
+		if s.Parent != "" && types[s.Parent] {
+			container = SymbolID(path, s.Parent)
+		}
+		a.Graph.AddEdge(container, id, domain.EdgeTypeContains)
+	}
+
+	for _, id := range a.containedSymbols(path) {
+		if !current[id] {
+			a.Graph.RemoveNode(id)
+		}
+	}
+}
+
+// containedSymbols returns the IDs of the symbols reachable from a file through CONTAINS edges.
+func (a *Analyzer) containedSymbols(path string) []string {
+	var ids []string
+	visited := map[string]bool{path: true}
+	queue := []string{path}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, edge := range a.Graph.GetEdgesFrom(current) {
+			if edge.Type != domain.EdgeTypeContains || visited[edge.TargetID] {
+				continue
+			}
+			visited[edge.TargetID] = true
+			ids = append(ids, edge.TargetID)
+			queue = append(queue, edge.TargetID)
+		}
+	}
+	return ids
+}
+
+// RemoveFile removes a file, the symbols, tests, ports and adapters it contains and the requirements it declares
+// from the graph, along with its Go package once it has no more files and the external packages no other file imports.
+func (a *Analyzer) RemoveFile(path string) {
+	if a.dropPortsAndAdapters(path, a.portsAndAdaptersOf(path)) {
+		a.linkStructuralAdapters("")
+	}
+	for _, id := range a.containedSymbols(path) {
+		a.Graph.RemoveNode(id)
+	}
+	for _, id := range a.testsOf(path) {
+		a.Graph.RemoveNode(id)
+	}
+	for _, n := range a.requirementsDeclaredIn(path) {
+		a.Graph.RemoveNode(n.ID)
+	}
+	a.unindexJVMFile(path)
+	imports := a.Graph.GetEdgesFrom(path)
+	a.Graph.RemoveNode(path)
+	a.prunePackage(filepath.Dir(path))
+	for _, edge := range imports {
+		if edge.Type == domain.EdgeTypeImports {
+			a.pruneExternal(edge.TargetID)
+		}
+	}
+}
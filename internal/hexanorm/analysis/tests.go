@@ -0,0 +1,57 @@
+package analysis
+
+import (
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/parser"
+)
+
+// analyzeTests adds a Test node for every unit or integration test declared in a code file,
+// and records their IDs on the file node, returning its updated version. Tests that were removed from the file are pruned.
+// IndexCallGraph links the tests with VERIFIES edges to the code they import or call.
+func (a *Analyzer) analyzeTests(file *domain.Node, content []byte, lang parser.Language, previous []string) *domain.Node {
+	tests, err := parser.ParseTests(content, file.ID, lang)
+	if err != nil {
+		return file
+	}
+
+	var ids []string
+	for _, t := range tests {
+		id := "test:" + file.ID + "#" + t.Name
+		ids = appendUnique(ids, id)
+		a.Graph.AddNode(&domain.Node{
+			ID:   id,
+			Kind: domain.NodeKindTest,
+			Properties: map[string]interface{}{
+				"name":       t.Name,
+				"framework":  t.Framework,
+				"file":       file.ID,
+				"start_line": t.StartLine,
+				"end_line":   t.EndLine,
+			},
+			Metadata: map[string]interface{}{
+				"layer":    file.Metadata["layer"],
+				"context":  file.Metadata["context"],
+				"language": string(lang),
+			},
+		})
+	}
+
+	for _, id := range previous {
+		if !contains(ids, id) {
+			a.Graph.RemoveNode(id)
+		}
+	}
+	if len(ids) > 0 || len(previous) > 0 {
+		file = withProperties(file, map[string]interface{}{"tests": ids})
+		a.Graph.AddNode(file)
+	}
+	return file
+}
+
+// testsOf returns the IDs of the Test nodes declared in a file.
+func (a *Analyzer) testsOf(path string) []string {
+	if n, ok := a.Graph.GetNode(path); ok {
+		return stringSlice(n.Properties["tests"])
+	}
+	return nil
+}
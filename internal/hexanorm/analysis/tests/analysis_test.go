@@ -0,0 +1,1277 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/analysis"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/config"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/graph"
+)
+
+// analyze runs the analyzer over in-memory files keyed by path.
+// Manifests such as go.mod are analyzed first, then the other files in path order.
+func analyze(t *testing.T, cfg *config.Config, files map[string]string) *analysis.Analyzer {
+	t.Helper()
+	an := analysis.NewAnalyzer(graph.NewGraph(nil), cfg)
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if mi, mj := analysis.IsManifest(paths[i]), analysis.IsManifest(paths[j]); mi != mj {
+			return mi
+		}
+		return paths[i] < paths[j]
+	})
+	for _, path := range paths {
+		if err := an.AnalyzeFile(path, []byte(files[path])); err != nil {
+			t.Fatalf("AnalyzeFile(%s) failed: %v", path, err)
+		}
+	}
+	return an
+}
+
+// writeFiles writes files keyed by path relative to root, for tests resolving imports on disk.
+// It returns their contents keyed by absolute path.
+func writeFiles(t *testing.T, root string, files map[string]string) map[string]string {
+	t.Helper()
+	abs := make(map[string]string)
+	for rel, content := range files {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		abs[path] = content
+	}
+	return abs
+}
+
+// countKind returns how many violations of the given kind were found.
+func countKind(violations []domain.Violation, kind domain.ViolationKind) int {
+	n := 0
+	for _, v := range violations {
+		if v.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+func TestContextIsolation(t *testing.T) {
+	files := map[string]string{
+		"/repo/src/domain/billing/Invoice.ts": `import { User } from '../user/internal/User';
+import { Money } from '../shared/Money';`,
+		"/repo/src/domain/billing/Payment.ts": `import { UserId } from '../user/api/UserId';`,
+	}
+
+	cfg := config.DefaultConfig
+	cfg.Contexts.SharedKernel = []string{"shared"}
+	if got := countKind(analyze(t, &cfg, files).FindViolations(), domain.ViolationKindArchContext); got != 0 {
+		t.Errorf("Expected no context violation without context inference, got %d", got)
+	}
+
+	cfg.Contexts.InferLayers = []string{"domain", "application"}
+	an := analyze(t, &cfg, files)
+
+	n, ok := an.Graph.GetNode("/repo/src/domain/billing/Invoice.ts")
+	if !ok || n.Metadata["context"] != "billing" {
+		t.Fatalf("Expected Invoice.ts to be in context billing, got %v", n)
+	}
+	if got := countKind(an.FindViolations(), domain.ViolationKindArchContext); got != 2 {
+		t.Errorf("Expected 2 context violations, got %d", got)
+	}
+
+	cfg.Contexts.PublicAPI = []string{"user/api/**"}
+	an = analyze(t, &cfg, files)
+	if got := countKind(an.FindViolations(), domain.ViolationKindArchContext); got != 1 {
+		t.Errorf("Expected 1 context violation with a public API, got %d", got)
+	}
+}
+
+func TestImportCycles(t *testing.T) {
+	root := t.TempDir()
+	an := analyze(t, nil, writeFiles(t, root, map[string]string{
+		"src/infrastructure/db/Repo.ts":        "import { Mapper } from '../mappers/Mapper';",
+		"src/infrastructure/mappers/Mapper.ts": "import { Row } from './Row';",
+		"src/infrastructure/mappers/Row.ts":    "import { Repo } from '../db/Repo';\nimport { Column } from './Column';",
+		"src/infrastructure/mappers/Column.ts": "import { Row } from './Row';",
+		"src/infrastructure/Bus.ts":            "import { Repo } from './db/Repo';",
+	}))
+
+	var targets []string
+	var fileCycle domain.Violation
+	for _, v := range an.FindViolations() {
+		if v.Kind == domain.ViolationKindImportCycle {
+			targets = append(targets, strings.ReplaceAll(v.Target, root, ""))
+			if v.RuleID == "cycle/file" {
+				fileCycle = v
+			}
+		}
+	}
+	want := []string{
+		"/src/infrastructure/db/Repo.ts -> /src/infrastructure/mappers/Mapper.ts -> /src/infrastructure/mappers/Row.ts -> /src/infrastructure/db/Repo.ts",
+		"/src/infrastructure/db -> /src/infrastructure/mappers -> /src/infrastructure/db",
+	}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("Cycle violations = %q, want %q", targets, want)
+	}
+	// The file cycle is reported at the import of the next file in the cycle
+	if fileCycle.File != filepath.Join(root, "src/infrastructure/db/Repo.ts") || fileCycle.Line != 1 || fileCycle.Column == 0 {
+		t.Errorf("Expected the file cycle at the first import of Repo.ts, got %+v", fileCycle)
+	}
+	// The file cycle lists the files of its other cycles
+	if !strings.Contains(fileCycle.Message, "4 files are tangled") || !strings.Contains(fileCycle.Message, "/src/infrastructure/mappers/Column.ts") {
+		t.Errorf("Expected the file cycle to list all its files, got %q", fileCycle.Message)
+	}
+}
+
+func TestGoImportCycles(t *testing.T) {
+	root := t.TempDir()
+	an := analyze(t, nil, writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.22\n",
+		"internal/orders/orders.go": `package orders
+
+import "example.com/shop/internal/billing"
+
+func Place() { billing.Charge() }`,
+		"internal/billing/billing.go": `package billing
+
+import "example.com/shop/internal/orders"
+
+func Charge() { orders.Place() }`,
+	}))
+
+	var targets []string
+	for _, v := range an.FindViolations() {
+		if v.Kind == domain.ViolationKindImportCycle {
+			targets = append(targets, v.RuleID+" "+strings.ReplaceAll(v.Target, root, ""))
+		}
+	}
+	// Go files import packages, so the cycle shows between packages only
+	want := []string{"cycle/package /internal/billing -> /internal/orders -> /internal/billing"}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("Cycle violations = %q, want %q", targets, want)
+	}
+}
+
+func TestPortsAndAdapters(t *testing.T) {
+	an := analyze(t, nil, map[string]string{
+		"/repo/go.mod": "module example.com/shop\n",
+		"/repo/domain/ports/ports.go": `package ports
+type OrderRepository interface { Save(o Order) error }
+type Notifier interface { Notify(msg string) error }`,
+		"/repo/infrastructure/pg/repo.go": `package pg
+type PgOrderRepository struct{}
+func (r *PgOrderRepository) Save(o Order) error { return nil }`,
+		"/repo/infrastructure/pg/row.go": `package pg
+type OrderRow struct{}
+func (r OrderRow) Columns() []string { return nil }`,
+		"/repo/infrastructure/adapters/cache/cache.go": `package cache
+type Cache struct{}
+func (c *Cache) Get(key string) string { return "" }`,
+		"/repo/application/checkout/checkout.go": `package checkout
+import "example.com/shop/infrastructure/pg"`,
+	})
+
+	port := "port:/repo/domain/ports/ports.go#OrderRepository"
+	adapter := "adapter:/repo/infrastructure/pg/repo.go#PgOrderRepository"
+	if !hasEdge(an.Graph.GetEdgesFrom(adapter), port, domain.EdgeTypeImplements) {
+		t.Errorf("Expected %s to IMPLEMENT %s", adapter, port)
+	}
+	if _, ok := an.Graph.GetNode("adapter:/repo/infrastructure/pg/row.go#OrderRow"); ok {
+		t.Error("Expected a type implementing no port outside the adapter paths not to be an adapter")
+	}
+
+	violations := an.FindViolations()
+	for kind, want := range map[domain.ViolationKind]int{
+		domain.ViolationKindPortWithoutAdapter: 1, // Notifier
+		domain.ViolationKindAdapterWithoutPort: 1, // Cache, in an adapter path
+		domain.ViolationKindAdapterDirectUse:   1, // checkout -> pg
+	} {
+		if got := countKind(violations, kind); got != want {
+			t.Errorf("Expected %d %s violations, got %d", want, kind, got)
+		}
+	}
+}
+
+func TestPortsAndAdaptersReanalysis(t *testing.T) {
+	an := analyze(t, nil, map[string]string{
+		"/repo/go.mod": "module example.com/shop\n",
+		// The adapter is analyzed before the port it implements
+		"/repo/infrastructure/mail/smtp.go": `package mail
+type SmtpMailer struct{}
+func (m *SmtpMailer) Send(to string) error { return nil }`,
+		"/repo/ports/mailer.go": `package ports
+type Mailer interface { Send(to string) error }`,
+		"/repo/src/domain/Clock.ts": `export interface Clock { now(): Date; }`,
+		"/repo/src/infrastructure/SystemClock.ts": `import { Clock } from '../domain/Clock';
+export class SystemClock implements Clock { now() { return new Date(); } }
+export class ClockDto { toJSON() { return {}; } }`,
+	})
+
+	mailer, smtp := "port:/repo/ports/mailer.go#Mailer", "adapter:/repo/infrastructure/mail/smtp.go#SmtpMailer"
+	clock, systemClock := "port:/repo/src/domain/Clock.ts#Clock", "adapter:/repo/src/infrastructure/SystemClock.ts#SystemClock"
+	if !hasEdge(an.Graph.GetEdgesFrom(smtp), mailer, domain.EdgeTypeImplements) || !hasEdge(an.Graph.GetEdgesFrom(systemClock), clock, domain.EdgeTypeImplements) {
+		t.Fatalf("Expected the adapters to IMPLEMENT their ports, got %v and %v", an.Graph.GetEdgesFrom(smtp), an.Graph.GetEdgesFrom(systemClock))
+	}
+	if _, ok := an.Graph.GetNode("adapter:/repo/src/infrastructure/SystemClock.ts#ClockDto"); ok {
+		t.Error("Expected a class implementing nothing not to be an adapter")
+	}
+
+	// Renaming a port drops the old one and its edges
+	if err := an.AnalyzeFile("/repo/src/domain/Clock.ts", []byte(`export interface TimeSource { now(): Date; }`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := an.Graph.GetNode(clock); ok {
+		t.Errorf("Expected the renamed port %s to be removed", clock)
+	}
+	if len(an.Graph.GetEdgesFrom(systemClock)) != 0 {
+		t.Errorf("Expected %s to implement no port anymore, got %v", systemClock, an.Graph.GetEdgesFrom(systemClock))
+	}
+
+	// A Go type is an adapter only while it declares every method of a port
+	if err := an.AnalyzeFile("/repo/ports/mailer.go", []byte(`package ports
+type Mailer interface { Send(to string) error; Close() error }`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := an.Graph.GetNode(smtp); ok {
+		t.Errorf("Expected %s to be no adapter once the port gained a method", smtp)
+	}
+
+	violations := an.FindViolations()
+	for kind, want := range map[domain.ViolationKind]int{
+		domain.ViolationKindPortWithoutAdapter: 2, // TimeSource, Mailer
+		domain.ViolationKindAdapterWithoutPort: 1, // SystemClock
+	} {
+		if got := countKind(violations, kind); got != want {
+			t.Errorf("Expected %d %s violations, got %d", want, kind, got)
+		}
+	}
+
+	// Removing a file removes its ports and adapters
+	an.RemoveFile("/repo/src/infrastructure/SystemClock.ts")
+	an.RemoveFile("/repo/ports/mailer.go")
+	for _, n := range an.Graph.GetAllNodes() {
+		if n.Kind == domain.NodeKindPort || n.Kind == domain.NodeKindAdapter {
+			if file := n.Properties["file"]; file != "/repo/src/domain/Clock.ts" {
+				t.Errorf("Expected the ports and adapters of removed files to be removed, got %s", n.ID)
+			}
+		}
+	}
+}
+
+// hasEdge reports whether the edges contain one to the target with the given type.
+func hasEdge(edges []*domain.Edge, targetID string, edgeType domain.EdgeType) bool {
+	for _, e := range edges {
+		if e.TargetID == targetID && e.Type == edgeType {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSymbols(t *testing.T) {
+	path := "/repo/src/domain/vat.ts"
+	an := analyze(t, nil, map[string]string{path: `export class VatService {
+  calculate(amount: number): number {
+    return amount * this.rate();
+  }
+  rate(): number { return 0.21; }
+}
+export const round = (n: number) => Math.round(n);`})
+
+	method := analysis.SymbolID(path, "VatService.calculate")
+	n, ok := an.Graph.GetNode(method)
+	if !ok || n.Kind != domain.NodeKindSymbol || n.Properties["start_line"] != 2 || n.Properties["end_line"] != 4 {
+		t.Fatalf("Expected method symbol spanning lines 2-4, got %v", n)
+	}
+	if !hasEdge(an.Graph.GetEdgesFrom(path), analysis.SymbolID(path, "VatService"), domain.EdgeTypeContains) ||
+		!hasEdge(an.Graph.GetEdgesFrom(path), analysis.SymbolID(path, "round"), domain.EdgeTypeContains) ||
+		!hasEdge(an.Graph.GetEdgesFrom(analysis.SymbolID(path, "VatService")), method, domain.EdgeTypeContains) {
+		t.Errorf("Expected CONTAINS edges from file to types and functions, and from types to methods")
+	}
+
+	// A requirement linked to the file is impacted by a change to the method.
+	an.Graph.AddNode(&domain.Node{ID: "REQ-1", Kind: domain.NodeKindRequirement})
+	an.Graph.AddEdge("REQ-1", path, domain.EdgeTypeImplementedBy)
+	if _, reqs := an.Graph.BlastRadius(method); !reflect.DeepEqual(reqs, []string{"REQ-1"}) {
+		t.Errorf("Expected REQ-1 in the blast radius of %s, got %v", method, reqs)
+	}
+
+	// Re-analyzing the file prunes removed symbols.
+	if err := an.AnalyzeFile(path, []byte(`export class VatService {}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := an.Graph.GetNode(method); ok {
+		t.Errorf("Expected %s to be pruned", method)
+	}
+	an.RemoveFile(path)
+	if _, ok := an.Graph.GetNode(analysis.SymbolID(path, "VatService")); ok {
+		t.Errorf("Expected symbols to be removed with their file")
+	}
+}
+
+func TestCallGraph(t *testing.T) {
+	an := analyze(t, nil, map[string]string{
+		"/repo/go.mod": "module example.com/shop\n",
+		"/repo/domain/vat.go": `package domain
+
+type VatService struct{}
+
+func NewVatService() VatService { return VatService{} }
+
+func (s VatService) Calculate(amount int) int { return s.rate() * amount }
+
+func (s VatService) rate() int { return round(21) }
+
+func round(n int) int { return n }`,
+		"/repo/application/checkout.go": `package application
+
+import "example.com/shop/domain"
+
+func Checkout() int {
+	svc := domain.NewVatService()
+	return svc.Calculate(10)
+}`,
+		"/repo/web/vat.ts": `export class VatService {
+  calculate(amount: number): number { return amount; }
+}`,
+		"/repo/test/steps/checkout.steps.ts": `import { VatService } from '../../web/vat';
+Given('a cart', () => {
+  new VatService().calculate(1);
+});`,
+	})
+	an.IndexCallGraph()
+
+	calls := [][2]string{
+		{"/repo/application/checkout.go#Checkout", "/repo/domain/vat.go#NewVatService"},
+		{"/repo/application/checkout.go#Checkout", "/repo/domain/vat.go#VatService.Calculate"},
+		{"/repo/domain/vat.go#VatService.Calculate", "/repo/domain/vat.go#VatService.rate"},
+		{"/repo/domain/vat.go#VatService.rate", "/repo/domain/vat.go#round"},
+		{"stepdef::a cart", "/repo/web/vat.ts#VatService.calculate"},
+	}
+	for _, call := range calls {
+		if !hasEdge(an.Graph.GetEdgesFrom(call[0]), call[1], domain.EdgeTypeCalls) {
+			t.Errorf("Expected %s to call %s, got %v", call[0], call[1], an.Graph.GetEdgesFrom(call[0]))
+		}
+	}
+
+	// Changing a file resolves again its calls and those of the files importing its package
+	vat := `package domain
+
+type VatService struct{}
+
+func NewVatService() VatService { return VatService{} }
+`
+	for _, content := range []string{vat, vat + "\nfunc (s VatService) Calculate(amount int) int { return amount }\n"} {
+		if err := an.AnalyzeFile("/repo/domain/vat.go", []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+		an.IndexCallGraphOf("/repo/domain/vat.go")
+	}
+	if !hasEdge(an.Graph.GetEdgesFrom("/repo/application/checkout.go#Checkout"), "/repo/domain/vat.go#VatService.Calculate", domain.EdgeTypeCalls) {
+		t.Errorf("Expected the importing file to call the method again, got %v", an.Graph.GetEdgesFrom("/repo/application/checkout.go#Checkout"))
+	}
+	if hasEdge(an.Graph.GetEdgesFrom("/repo/domain/vat.go#VatService.Calculate"), "/repo/domain/vat.go#VatService.rate", domain.EdgeTypeCalls) {
+		t.Error("Expected the calls of the changed method to be resolved again")
+	}
+}
+
+func TestRequirementTags(t *testing.T) {
+	feature := "/repo/features/vat.feature"
+	an := analyze(t, nil, map[string]string{
+		feature: `@REQ-1
+Feature: VAT
+  @REQ-2 @smoke
+  Scenario: Apply VAT
+    Given a price of 100
+`,
+		"/repo/src/vat.ts": `export function applyVat(n: number) { return n * 1.21; }`,
+		"/repo/test/steps/vat.steps.ts": `import { applyVat } from '../../src/vat';
+Given('a price of {int}', (n) => { applyVat(n); });`,
+	})
+	an.IndexStepDefinitions()
+	an.IndexCallGraph()
+
+	if !hasEdge(an.Graph.GetEdgesFrom("REQ-1"), "gh:feat:VAT", domain.EdgeTypeDescribedBy) {
+		t.Errorf("Expected REQ-1 to be DESCRIBED_BY the feature")
+	}
+	scenario := an.Graph.GetEdgesFrom("gh:scen:Apply_VAT")
+	if !hasEdge(scenario, "REQ-1", domain.EdgeTypeVerifies) || !hasEdge(scenario, "REQ-2", domain.EdgeTypeVerifies) || hasEdge(scenario, "smoke", domain.EdgeTypeVerifies) {
+		t.Errorf("Expected the scenario to verify REQ-1 and REQ-2 only, got %v", scenario)
+	}
+	_, reqs := an.Graph.BlastRadius("/repo/src/vat.ts#applyVat")
+	sort.Strings(reqs)
+	if !reflect.DeepEqual(reqs, []string{"REQ-1", "REQ-2"}) {
+		t.Errorf("Expected REQ-1 and REQ-2 in the blast radius of applyVat, got %v", reqs)
+	}
+
+	// Removing a tag removes its link
+	if err := an.AnalyzeFile(feature, []byte("Feature: VAT\n  Scenario: Apply VAT\n    Given a price of 100\n")); err != nil {
+		t.Fatal(err)
+	}
+	if len(an.Graph.GetEdgesTo("gh:feat:VAT")) != 0 || len(an.Graph.GetEdgesFrom("gh:scen:Apply_VAT")) != 1 {
+		t.Errorf("Expected requirement links to be removed, got %v", an.Graph.GetEdgesFrom("gh:scen:Apply_VAT"))
+	}
+}
+
+func TestRequirementFiles(t *testing.T) {
+	path := "/repo/docs/requirements/REQ-42.md"
+	an := analyze(t, nil, map[string]string{
+		path: `---
+id: REQ-42
+title: "Invoices include VAT"
+status: approved
+priority: high
+features: [Invoicing]
+acceptance_criteria:
+  - VAT is shown per line
+  - Totals include VAT # rounded
+---
+# Invoices include VAT
+
+Customers must see the VAT they pay.
+`,
+		"/repo/docs/guide.md": "---\nid: NOT-A-REQ\n---\n",
+	})
+
+	n, ok := an.Graph.GetNode("REQ-42")
+	if !ok || n.Kind != domain.NodeKindRequirement {
+		t.Fatalf("Expected requirement REQ-42, got %v", n)
+	}
+	if n.Properties["title"] != "Invoices include VAT" || n.Properties["status"] != "approved" || n.Properties["description"] != "# Invoices include VAT\n\nCustomers must see the VAT they pay." {
+		t.Errorf("Unexpected requirement properties %v", n.Properties)
+	}
+	if criteria := n.Properties["acceptanceCriteria"]; !reflect.DeepEqual(criteria, []interface{}{"VAT is shown per line", "Totals include VAT"}) {
+		t.Errorf("Unexpected acceptance criteria %v", criteria)
+	}
+	if !hasEdge(an.Graph.GetEdgesFrom("REQ-42"), "feature:Invoicing", domain.EdgeTypeDefines) {
+		t.Errorf("Expected REQ-42 to define the Invoicing feature")
+	}
+	if _, ok := an.Graph.GetNode("NOT-A-REQ"); ok {
+		t.Errorf("Expected files outside the requirements directories to be ignored")
+	}
+
+	an.RemoveFile(path)
+	if _, ok := an.Graph.GetNode("REQ-42"); ok {
+		t.Errorf("Expected REQ-42 to be removed with its file")
+	}
+}
+
+func TestTestNodes(t *testing.T) {
+	an := analyze(t, nil, map[string]string{
+		"/repo/domain/vat.go": `package domain
+
+func Calculate(amount int) int { return round(amount * 121 / 100) }
+
+func round(n int) int { return n }`,
+		"/repo/domain/vat_test.go": `package domain
+
+import "testing"
+
+func TestCalculate(t *testing.T) {
+	if Calculate(100) != 121 {
+		t.Fail()
+	}
+}`,
+		"/repo/web/cart.ts": `export function total(items: number[]) { return items.length; }`,
+		"/repo/web/cart.test.ts": `import { total } from './cart';
+describe('Cart', () => {
+  it('sums items', () => { expect(total([1])).toBe(1); });
+});`,
+	})
+	an.IndexCallGraph()
+
+	goTest := "test:/repo/domain/vat_test.go#TestCalculate"
+	if n, ok := an.Graph.GetNode(goTest); !ok || n.Kind != domain.NodeKindTest {
+		t.Fatalf("Expected Go test node, got %v", n)
+	}
+	if !hasEdge(an.Graph.GetEdgesFrom(goTest), "/repo/domain/vat.go#Calculate", domain.EdgeTypeVerifies) {
+		t.Errorf("Expected the Go test to verify Calculate, got %v", an.Graph.GetEdgesFrom(goTest))
+	}
+	jsTest := "test:/repo/web/cart.test.ts#Cart > sums items"
+	if !hasEdge(an.Graph.GetEdgesFrom(jsTest), "/repo/web/cart.ts#total", domain.EdgeTypeVerifies) {
+		t.Errorf("Expected the Jest test to verify total, got %v", an.Graph.GetEdgesFrom(jsTest))
+	}
+
+	if tests := an.Graph.CoveringTests("/repo/domain/vat.go#round"); !reflect.DeepEqual(tests, []string{goTest}) {
+		t.Errorf("Expected round to be covered through Calculate, got %v", tests)
+	}
+	if tests := an.Graph.CoveringTests("/repo/web/cart.ts"); !reflect.DeepEqual(tests, []string{jsTest}) {
+		t.Errorf("Expected cart.ts to be covered by its test, got %v", tests)
+	}
+}
+
+func TestJVMImports(t *testing.T) {
+	an := analyze(t, nil, map[string]string{
+		"/repo/billing/pom.xml": `<project>
+  <build>
+    <sourceDirectory>${project.basedir}/src/java</sourceDirectory>
+  </build>
+</project>`,
+		"/repo/billing/src/java/com/acme/domain/Invoice.java": `package com.acme.domain;
+
+import com.acme.infrastructure.JpaInvoices;
+import com.acme.shipping.Parcel;
+
+public class Invoice {}`,
+		// Analyzed after the file importing it, in the root of another project
+		"/repo/shipping/build.gradle": `sourceSets { main { java { srcDirs = ['src/java'] } } }`,
+		"/repo/shipping/src/java/com/acme/shipping/Parcel.java": `package com.acme.shipping;
+
+public class Parcel {}`,
+		"/repo/billing/src/java/com/acme/infrastructure/JpaInvoices.java": `package com.acme.infrastructure;
+
+public class JpaInvoices {}`,
+		"/repo/billing/src/main/kotlin/com/acme/application/Checkout.kt": `package com.acme.application
+
+import com.acme.domain.Invoice
+import com.acme.domain.*`,
+		"/repo/billing/src/test/java/com/acme/steps/InvoiceSteps.java": `package com.acme.steps;
+
+public class InvoiceSteps {
+    @Given("^an invoice of (\\d+) EUR$")
+    public void anInvoice(int amount) {}
+}`,
+	})
+
+	checkout := "/repo/billing/src/main/kotlin/com/acme/application/Checkout.kt"
+	if !hasEdge(an.Graph.GetEdgesFrom(checkout), "/repo/billing/src/java/com/acme/domain/Invoice.java", domain.EdgeTypeImports) {
+		t.Errorf("Expected the Kotlin class import to resolve to the Java file, got %v", an.Graph.GetEdgesFrom(checkout))
+	}
+	if !hasEdge(an.Graph.GetEdgesFrom(checkout), "/repo/billing/src/java/com/acme/domain", domain.EdgeTypeImports) {
+		t.Errorf("Expected the wildcard import to resolve to the package directory, got %v", an.Graph.GetEdgesFrom(checkout))
+	}
+
+	invoice := "/repo/billing/src/java/com/acme/domain/Invoice.java"
+	parcel := "/repo/shipping/src/java/com/acme/shipping/Parcel.java"
+	if !hasEdge(an.Graph.GetEdgesFrom(invoice), parcel, domain.EdgeTypeImports) ||
+		hasEdge(an.Graph.GetEdgesFrom(invoice), "/repo/billing/src/java/com/acme/shipping/Parcel.java", domain.EdgeTypeImports) {
+		t.Errorf("Expected the import of a class analyzed later to resolve to its file, got %v", an.Graph.GetEdgesFrom(invoice))
+	}
+	if n, _ := an.Graph.GetNode(invoice); n.Properties["import_positions"].(map[string]interface{})[parcel] == nil {
+		t.Errorf("Expected the import position to follow the resolved import, got %v", n.Properties["import_positions"])
+	}
+
+	var found bool
+	for _, v := range an.FindViolations() {
+		if v.Kind == domain.ViolationKindArchLayer && v.File == invoice {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected the domain class importing infrastructure to break the layer rules")
+	}
+
+	n, ok := an.Graph.GetNode(`stepdef:anInvoice:^an invoice of (\d+) EUR$`)
+	if !ok || n.Properties["line"] != 4 {
+		t.Errorf("Expected a Cucumber-JVM step definition, got %v", n)
+	}
+}
+
+func TestTSModuleResolution(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"tsconfig.base.json": `{
+  // Shared settings
+  "compilerOptions": {
+    "baseUrl": ".",
+    "paths": { "@domain/*": ["missing/*", "packages/core/src/domain/*"] },
+  }
+}`,
+		"apps/web/tsconfig.json":            `{ "extends": "../../tsconfig.base.json" }`,
+		"apps/web/src/components/index.tsx": `export const Button = () => <button />;`,
+		"apps/web/src/util.ts":              `export const noop = () => {};`,
+		"packages/core/src/domain/user.ts":  `export class User {}`,
+		"packages/shared/package.json":      `{ "name": "@acme/shared", "exports": { ".": { "types": "./src/index.ts", "default": "./dist/index.js" }, "./*": "./src/*.ts" } }`,
+		"packages/shared/src/index.ts":      `export * from './money';`,
+		"packages/shared/src/money.ts":      `export const eur = 1;`,
+		"apps/web/src/app.ts": `import { User } from '@domain/user';
+import { Button } from './components';
+import { noop } from './util.js';
+import { eur } from '@acme/shared';
+import * as money from '@acme/shared/money';
+import React from 'react';`,
+	}
+	an := analyze(t, nil, writeFiles(t, root, files))
+
+	app := filepath.Join(root, "apps/web/src/app.ts")
+	for _, want := range []string{
+		filepath.Join(root, "packages/core/src/domain/user.ts"),
+		filepath.Join(root, "apps/web/src/components/index.tsx"),
+		filepath.Join(root, "apps/web/src/util.ts"),
+		filepath.Join(root, "packages/shared/src/index.ts"),
+		filepath.Join(root, "packages/shared/src/money.ts"),
+		"react",
+	} {
+		if !hasEdge(an.Graph.GetEdgesFrom(app), want, domain.EdgeTypeImports) {
+			t.Errorf("Expected an import of %s, got %v", want, an.Graph.GetEdgesFrom(app))
+		}
+	}
+	if index := filepath.Join(root, "packages/shared/src/index.ts"); !hasEdge(an.Graph.GetEdgesFrom(index), filepath.Join(root, "packages/shared/src/money.ts"), domain.EdgeTypeImports) {
+		t.Errorf("Expected the re-export to resolve, got %v", an.Graph.GetEdgesFrom(index))
+	}
+}
+
+func TestPythonImports(t *testing.T) {
+	root := t.TempDir()
+	an := analyze(t, nil, writeFiles(t, root, map[string]string{
+		"pyproject.toml": `[tool.setuptools.packages.find]
+where = ["src"]`,
+		"src/myapp/__init__.py":          `VERSION = "1"`,
+		"src/myapp/domain/__init__.py":   ``,
+		"src/myapp/domain/user.py":       `class User: pass`,
+		"src/myapp/infrastructure/db.py": `def connect(): pass`,
+		"src/myapp/domain/order.py": `from .user import User
+from . import user
+from .. import VERSION
+from ..infrastructure import db`,
+		"src/myapp/application/service.py": `from myapp.domain.user import User
+from myapp.domain import order
+import myapp.infrastructure.db as db
+import requests`,
+	}))
+
+	pkg := filepath.Join(root, "src/myapp")
+	tests := map[string][]string{
+		"domain/order.py": {
+			filepath.Join(pkg, "domain/user.py"),
+			filepath.Join(pkg, "__init__.py"),
+			filepath.Join(pkg, "infrastructure/db.py"),
+		},
+		"application/service.py": {
+			filepath.Join(pkg, "domain/user.py"),
+			filepath.Join(pkg, "domain/order.py"),
+			filepath.Join(pkg, "infrastructure/db.py"),
+			"requests",
+		},
+	}
+	for file, want := range tests {
+		edges := an.Graph.GetEdgesFrom(filepath.Join(pkg, file))
+		for _, target := range want {
+			if !hasEdge(edges, target, domain.EdgeTypeImports) {
+				t.Errorf("Expected %s to import %s, got %v", file, target, edges)
+			}
+		}
+	}
+
+	var found bool
+	for _, v := range an.FindViolations() {
+		if v.Kind == domain.ViolationKindArchLayer && v.File == filepath.Join(pkg, "domain/order.py") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected the relative import of infrastructure from domain to break the layer rules")
+	}
+}
+
+func TestRustImports(t *testing.T) {
+	root := t.TempDir()
+	an := analyze(t, nil, writeFiles(t, root, map[string]string{
+		"Cargo.toml": `[workspace]
+members = ["domain", "application", "infrastructure"]`,
+		"domain/Cargo.toml": `[package]
+name = "hexa-domain"`,
+		"domain/src/lib.rs":       "pub mod user;\npub mod ports;",
+		"domain/src/ports/mod.rs": "pub trait Repo {}",
+		"domain/src/user.rs": `use crate::ports::Repo;
+use super::ports::Repo as R;
+use infra::db::Pg;`,
+		"application/Cargo.toml": `[package]
+name = "application"
+
+[dependencies]
+domain = { path = "../domain", package = "hexa-domain" }`,
+		"application/src/lib.rs": `use domain::{user::User, ports::Repo};
+use std::fmt;
+mod service;`,
+		"application/src/service.rs": "pub fn run() {}",
+		"infrastructure/Cargo.toml": `[package]
+name = "infra"`,
+		"infrastructure/src/lib.rs": "pub mod db;",
+		"infrastructure/src/db.rs":  "pub struct Pg;",
+	}))
+
+	tests := map[string][]string{
+		"domain/src/lib.rs":      {"domain/src/user.rs", "domain/src/ports/mod.rs"},
+		"domain/src/user.rs":     {"domain/src/ports/mod.rs", "infrastructure/src/db.rs"},
+		"application/src/lib.rs": {"domain/src/user.rs", "domain/src/ports/mod.rs", "application/src/service.rs"},
+	}
+	for file, want := range tests {
+		edges := an.Graph.GetEdgesFrom(filepath.Join(root, file))
+		for _, target := range want {
+			if !hasEdge(edges, filepath.Join(root, target), domain.EdgeTypeImports) {
+				t.Errorf("Expected %s to import %s, got %v", file, target, edges)
+			}
+		}
+	}
+	if !hasEdge(an.Graph.GetEdgesFrom(filepath.Join(root, "application/src/lib.rs")), "std::fmt", domain.EdgeTypeImports) {
+		t.Error("Expected external crates to be kept as written")
+	}
+
+	var found bool
+	for _, v := range an.FindViolations() {
+		if v.Kind == domain.ViolationKindArchLayer && v.File == filepath.Join(root, "domain/src/user.rs") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected the domain crate importing the infrastructure crate to break the layer rules")
+	}
+}
+
+func TestPHPImports(t *testing.T) {
+	root := t.TempDir()
+	an := analyze(t, nil, writeFiles(t, root, map[string]string{
+		"composer.json": `{
+  "autoload": {"psr-4": {"App\\": "src/", "App\\Legacy\\": ["lib/", "legacy/"]}},
+  "autoload-dev": {"psr-4": {"App\\Tests\\": "tests/"}}
+}`,
+		"src/domain/User.php": "<?php namespace App\\domain; class User {}",
+		"src/domain/Order.php": `<?php
+namespace App\domain;
+
+use App\domain\User;
+use App\infrastructure\{Db, Mailer as M};
+use Psr\Log\LoggerInterface;`,
+		"src/infrastructure/Db.php": "<?php namespace App\\infrastructure; class Db {}",
+		"legacy/Billing.php":        "<?php namespace App\\Legacy; class Billing {}",
+		"tests/OrderTest.php":       "<?php use App\\domain\\Order; use App\\Legacy\\Billing; use App\\Tests\\Support;",
+		"tests/Support.php":         "<?php namespace App\\Tests; class Support {}",
+	}))
+
+	tests := map[string][]string{
+		"src/domain/Order.php": {"src/domain/User.php", "src/infrastructure/Db.php", "src/infrastructure/Mailer.php"},
+		"tests/OrderTest.php":  {"src/domain/Order.php", "legacy/Billing.php", "tests/Support.php"},
+	}
+	for file, want := range tests {
+		edges := an.Graph.GetEdgesFrom(filepath.Join(root, file))
+		for _, target := range want {
+			if !hasEdge(edges, filepath.Join(root, target), domain.EdgeTypeImports) {
+				t.Errorf("Expected %s to import %s, got %v", file, target, edges)
+			}
+		}
+	}
+	if !hasEdge(an.Graph.GetEdgesFrom(filepath.Join(root, "src/domain/Order.php")), "Psr\\Log\\LoggerInterface", domain.EdgeTypeImports) {
+		t.Error("Expected vendor classes to be kept as written")
+	}
+
+	var found bool
+	for _, v := range an.FindViolations() {
+		if v.Kind == domain.ViolationKindArchLayer && v.File == filepath.Join(root, "src/domain/Order.php") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected the domain importing the infrastructure to break the layer rules")
+	}
+}
+
+func TestGoPackages(t *testing.T) {
+	an := analyze(t, nil, map[string]string{
+		"/repo/go.work":     "go 1.22\n\nuse (\n\t./core // domain module\n\t./app\n)\n",
+		"/repo/core/go.mod": "module example.com/core\n",
+		"/repo/core/domain/user.go": `package domain
+
+import "example.com/core/internal/ids"
+
+type User struct{ ID ids.ID }`,
+		"/repo/core/internal/ids/ids.go": "package ids\n\ntype ID string",
+		"/repo/app/go.mod":               "module example.com/app\n",
+		"/repo/app/application/service.go": `package application
+
+import (
+	"fmt"
+
+	"example.com/core/domain"
+	"example.com/core/internal/ids"
+)
+
+func Register(u domain.User) ids.ID { return fmt.Sprint(u.ID) }`,
+		"/repo/app/application/service_test.go": `package application
+
+import (
+	"testing"
+
+	"example.com/core/domain"
+)
+
+func TestRegister(t *testing.T) { Register(domain.User{}) }`,
+	})
+
+	pkg, ok := an.Graph.GetNode("/repo/core/domain")
+	if !ok || pkg.Kind != domain.NodeKindPackage || pkg.Properties["import_path"] != "example.com/core/domain" {
+		t.Fatalf("Expected a Package node for example.com/core/domain, got %+v", pkg)
+	}
+	if pkg.Metadata["layer"] != "domain" {
+		t.Errorf("Expected the package to take the layer of its files, got %v", pkg.Metadata["layer"])
+	}
+	if !hasEdge(an.Graph.GetEdgesFrom(pkg.ID), "/repo/core/domain/user.go", domain.EdgeTypeContains) {
+		t.Error("Expected the package to contain its file")
+	}
+
+	edges := an.Graph.GetEdgesFrom("/repo/app/application/service.go")
+	if !hasEdge(edges, "/repo/core/domain", domain.EdgeTypeImports) {
+		t.Errorf("Expected an import of a workspace module to resolve to its package, got %v", edges)
+	}
+	if !hasEdge(edges, "fmt", domain.EdgeTypeImports) {
+		t.Error("Expected standard library imports to be kept as written")
+	}
+
+	an.IndexCallGraph()
+	if !hasEdge(an.Graph.GetEdgesFrom("test:/repo/app/application/service_test.go#TestRegister"), "/repo/core/domain", domain.EdgeTypeVerifies) {
+		t.Error("Expected the test to verify the package it imports")
+	}
+	if tests := an.Graph.CoveringTests("/repo/core/domain/user.go"); len(tests) != 1 {
+		t.Errorf("Expected the file to be covered through its package, got %v", tests)
+	}
+
+	violations := an.FindViolations()
+	if n := countKind(violations, domain.ViolationKindInternalImport); n != 1 {
+		t.Errorf("Expected 1 internal import violation, got %d: %v", n, violations)
+	}
+	for _, v := range violations {
+		if v.Kind == domain.ViolationKindInternalImport && v.File != "/repo/app/application/service.go" {
+			t.Errorf("Expected only the other module to break internal visibility, got %s", v.File)
+		}
+	}
+
+	an.RemoveFile("/repo/core/internal/ids/ids.go")
+	if _, ok := an.Graph.GetNode("/repo/core/internal/ids"); ok {
+		t.Error("Expected the package to be removed with its last file")
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	files := map[string]string{
+		"/repo/src/domain/User.ts": `// @hexanorm:ignore ARCH_LAYER_VIOLATION reason="legacy"
+import { Db } from '../infrastructure/Db';
+export class User {}`,
+		"/repo/src/domain/Order.ts": `import { Db } from '../infrastructure/Db';
+// @hexanorm:implements REQ-42
+export class Order {}`,
+		"/repo/src/shared/Mailer.ts": `// @hexanorm:layer infrastructure
+// @hexanorm:implements REQ-42 REQ-43
+
+export function send() {}`,
+		"/repo/src/infrastructure/Db.ts": "export class Db {}",
+		"/repo/app/routes.py":            "# @hexanorm:implements REQ-44\n@app.route(\"/\")\ndef index():\n    pass\n",
+	}
+	an := analyze(t, nil, files)
+
+	if n, _ := an.Graph.GetNode("/repo/src/shared/Mailer.ts"); n.Metadata["layer"] != "infrastructure" || n.Metadata["layer_source"] != "annotation" {
+		t.Errorf("Expected the annotation to set the layer, got %v", n.Metadata)
+	}
+	if !hasEdge(an.Graph.GetEdgesFrom("REQ-42"), "/repo/src/domain/Order.ts#Order", domain.EdgeTypeImplementedBy) {
+		t.Errorf("Expected REQ-42 to be implemented by the annotated class, got %v", an.Graph.GetEdgesFrom("REQ-42"))
+	}
+	for _, req := range []string{"REQ-42", "REQ-43"} {
+		if !hasEdge(an.Graph.GetEdgesFrom(req), "/repo/src/shared/Mailer.ts", domain.EdgeTypeImplementedBy) {
+			t.Errorf("Expected %s to be implemented by the file", req)
+		}
+	}
+	if n, ok := an.Graph.GetNode("REQ-43"); !ok || n.Kind != domain.NodeKindRequirement {
+		t.Error("Expected annotated requirements to be added to the graph")
+	}
+	if !hasEdge(an.Graph.GetEdgesFrom("REQ-44"), "/repo/app/routes.py#index", domain.EdgeTypeImplementedBy) {
+		t.Errorf("Expected an annotation above a decorator to link the decorated function, got %v", an.Graph.GetEdgesFrom("REQ-44"))
+	}
+
+	violations := an.FindViolations()
+	for _, v := range violations {
+		if v.File == "/repo/src/domain/User.ts" {
+			t.Errorf("Expected the violation to be suppressed, got %v", v)
+		}
+	}
+	if n := countKind(violations, domain.ViolationKindArchLayer); n != 1 {
+		t.Errorf("Expected the unannotated file to keep its layer violation, got %v", violations)
+	}
+
+	// Removing an annotation removes its link, without touching nodes handed out before
+	before, _ := an.Graph.GetNode("/repo/src/shared/Mailer.ts")
+	if err := an.AnalyzeFile("/repo/src/shared/Mailer.ts", []byte("// @hexanorm:implements REQ-43\n\nexport function send() {}")); err != nil {
+		t.Fatal(err)
+	}
+	if hasEdge(an.Graph.GetEdgesFrom("REQ-42"), "/repo/src/shared/Mailer.ts", domain.EdgeTypeImplementedBy) {
+		t.Error("Expected the link of the removed annotation to be removed")
+	}
+	if !hasEdge(an.Graph.GetEdgesFrom("REQ-43"), "/repo/src/shared/Mailer.ts", domain.EdgeTypeImplementedBy) {
+		t.Error("Expected the remaining annotation to keep its link")
+	}
+	if got := before.Properties["implements"]; !reflect.DeepEqual(got, []string{"REQ-42", "REQ-43"}) {
+		t.Errorf("Expected the previous node to be left unchanged, got %v", got)
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	files := func(root string) map[string]string {
+		return map[string]string{
+			root + "/src/domain/User.ts":        "import { Db } from '../infrastructure/Db';",
+			root + "/src/domain/Order.ts":       "import { Db } from '../infrastructure/Db';",
+			root + "/src/infrastructure/Db.ts":  "export class Db {}",
+			root + "/src/infrastructure/Bus.ts": "export class Bus {}",
+			root + "/src/domain/Mailer.ts":      "export interface Mailer { send(): void; }",
+		}
+	}
+	analyzeAt := func(root string) *analysis.Analyzer {
+		an := analyze(t, nil, files(root))
+		an.RootDir = root
+		return an
+	}
+
+	an := analyzeAt("/repo")
+	violations := an.FindViolations()
+	if countKind(violations, domain.ViolationKindArchLayer) != 2 {
+		t.Fatalf("Expected 2 layer violations, got %v", violations)
+	}
+	fingerprints := make(map[string]bool)
+	for _, v := range violations {
+		if v.Fingerprint == "" || v.Baselined {
+			t.Errorf("Expected a fingerprinted, new violation, got %+v", v)
+		}
+		fingerprints[v.Fingerprint] = true
+	}
+	for _, root := range []string{"/elsewhere/checkout", "checkout"} {
+		for _, v := range analyzeAt(root).FindViolations() {
+			if !fingerprints[v.Fingerprint] {
+				t.Errorf("Expected fingerprints to be independent of the root %q, got %+v", root, v)
+			}
+		}
+	}
+
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	b := an.NewBaseline(violations, nil, "2099-12-31", "legacy monolith", now)
+	path := filepath.Join(t.TempDir(), "hexanorm-baseline.json")
+	if err := b.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := analysis.LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Entries) != len(violations) || loaded.Entries[0].File != "src/domain/Mailer.ts" || loaded.Entries[0].Added != "2026-10-16" {
+		t.Fatalf("Expected the entries to round-trip with relative paths, got %+v", loaded.Entries)
+	}
+	if target := loaded.Entries[0].Target; target != "port:src/domain/Mailer.ts#Mailer" {
+		t.Errorf("Expected the port's node ID to be relative, got %q", target)
+	}
+	if err := os.WriteFile(path, []byte(`{"entries": [{"fingerprint": "3f9a0c21d4e7b865", "expires": "30/06/2027"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := analysis.LoadBaseline(path); err == nil {
+		t.Error("Expected a malformed expiry date to be rejected")
+	}
+
+	// Only the new violation is reported as new
+	an.Baseline = loaded
+	an.AnalyzeFile("/repo/src/domain/Invoice.ts", []byte("import { Bus } from '../infrastructure/Bus';"))
+	var fresh []domain.Violation
+	for _, v := range an.FindViolations() {
+		if !v.Baselined {
+			fresh = append(fresh, v)
+		}
+	}
+	if len(fresh) != 1 || fresh[0].File != "/repo/src/domain/Invoice.ts" {
+		t.Errorf("Expected only the new violation to be new, got %v", fresh)
+	}
+
+	// Regenerating keeps the accepted entries as they were
+	next := an.NewBaseline(an.FindViolations(), loaded, "", "", now.AddDate(0, 1, 0))
+	for _, e := range next.Entries {
+		if e.File == "src/domain/User.ts" && (e.Justification != "legacy monolith" || e.Added != "2026-10-16") {
+			t.Errorf("Expected the entry to keep its justification and date, got %+v", e)
+		}
+	}
+
+	if loaded.Accepts(loaded.Entries[0].Fingerprint, time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("Expected expired entries to no longer accept the violation")
+	}
+}
+
+func TestViolationLocations(t *testing.T) {
+	an := analyze(t, nil, map[string]string{
+		"/repo/src/domain/User.ts": `export class User {}
+
+import { S3Bucket } from '../infrastructure/S3Bucket';`,
+	})
+
+	violations := an.FindViolations()
+	if len(violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %v", violations)
+	}
+	v := violations[0]
+	if v.Line != 3 || v.Column != 27 || v.EndLine != 3 || v.EndColumn != 53 {
+		t.Errorf("Expected the violation at the import specifier 3:27-3:53, got %d:%d-%d:%d", v.Line, v.Column, v.EndLine, v.EndColumn)
+	}
+	if v.RuleID != "layer/domain" || v.Target != "/repo/src/infrastructure/S3Bucket" || v.Remediation == "" {
+		t.Errorf("Expected the rule, target and remediation of the layer rule, got %+v", v)
+	}
+
+	// Ports are located at their declaration
+	an = analyze(t, nil, map[string]string{
+		"/repo/src/domain/Users.ts": `// Users persists users.
+
+export interface Users {
+  save(): void;
+}`,
+	})
+	violations = an.FindViolations()
+	if len(violations) != 1 || violations[0].Kind != domain.ViolationKindPortWithoutAdapter {
+		t.Fatalf("Expected 1 port violation, got %v", violations)
+	}
+	if v := violations[0]; v.Line != 3 || v.EndLine != 5 {
+		t.Errorf("Expected the port violation at lines 3-5, got %d-%d", v.Line, v.EndLine)
+	}
+}
+
+func TestExternalRules(t *testing.T) {
+	cfg := config.DefaultConfig
+	cfg.ExternalRules = []config.ExternalRule{
+		{Layer: "domain", Allow: []string{"stdlib", "github.com/shopspring/decimal"}, Severity: domain.SeverityCritical},
+		{Layer: "application", Forbid: []string{"@aws-sdk/*"}, Severity: domain.SeverityWarning},
+	}
+	an := analyze(t, &cfg, map[string]string{
+		"/repo/go.mod": "module example.com/shop",
+		"/repo/internal/domain/money.go": `package domain
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal/v2"
+	"github.com/aws/aws-sdk-go/service/s3"
+)`,
+		"/repo/src/application/Upload.ts": `import { S3Client } from '@aws-sdk/client-s3';
+import { readFile } from 'node:fs/promises';`,
+	})
+
+	n, ok := an.Graph.GetNode("fmt")
+	if !ok || n.Kind != domain.NodeKindExternal || n.Properties["stdlib"] != true {
+		t.Errorf("Expected fmt to be a standard library External node, got %v", n)
+	}
+	if n, ok := an.Graph.GetNode("@aws-sdk/client-s3"); !ok || n.Kind != domain.NodeKindExternal || n.Properties["stdlib"] != false {
+		t.Errorf("Expected @aws-sdk/client-s3 to be a third-party External node, got %v", n)
+	}
+
+	var targets []string
+	for _, v := range an.FindViolations() {
+		if v.Kind == domain.ViolationKindExternalImport {
+			targets = append(targets, v.Target)
+		}
+	}
+	sort.Strings(targets)
+	want := []string{"@aws-sdk/client-s3", "github.com/aws/aws-sdk-go/service/s3"}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("Expected external violations for %v, got %v", want, targets)
+	}
+
+	an.RemoveFile("/repo/src/application/Upload.ts")
+	if _, ok := an.Graph.GetNode("@aws-sdk/client-s3"); ok {
+		t.Error("Expected the External node to be removed with the last file importing it")
+	}
+}
+
+func TestDefaultLayerRules(t *testing.T) {
+	an := analyze(t, nil, map[string]string{
+		"/repo/src/domain/User.ts":          "import { Dto } from '../interface/Dto';\nimport { Db } from '../infrastructure/Db';",
+		"/repo/src/application/Register.ts": "import { Dto } from '../interface/Dto';\nimport { Db } from '../infrastructure/Db';",
+		"/repo/src/interface/Dto.ts":        "export class Dto {}",
+		"/repo/src/infrastructure/Db.ts":    "export class Db {}",
+	})
+
+	var got []string
+	for _, v := range an.FindViolations() {
+		if v.Kind == domain.ViolationKindArchLayer {
+			got = append(got, fmt.Sprintf("%s %s -> %s", v.Severity, v.File, v.Target))
+		}
+	}
+	sort.Strings(got)
+	want := []string{
+		"CRITICAL /repo/src/domain/User.ts -> /repo/src/infrastructure/Db",
+		"WARNING /repo/src/application/Register.ts -> /repo/src/infrastructure/Db",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected only infrastructure imports to break the default rules, got %v", got)
+	}
+
+	// The analyzer gets its own copy of the defaults
+	an.Config.CycleSeverity = domain.SeverityCritical
+	if config.DefaultConfig.CycleSeverity != domain.SeverityWarning {
+		t.Error("Expected the default configuration to be left unchanged")
+	}
+}
+
+func TestLayerLeaks(t *testing.T) {
+	root := t.TempDir()
+	files := writeFiles(t, root, map[string]string{
+		"src/domain/User.ts":           "import { slug } from '../shared/utils';",
+		"src/shared/utils.ts":          "import { format } from './format';",
+		"src/shared/format.ts":         "import { S3 } from '../infrastructure/S3';",
+		"src/infrastructure/S3.ts":     "export class S3 {}",
+		"src/application/Register.ts":  "import { User } from '../domain/User';",
+		"src/application/Checkout.ts":  "import { slug } from '../shared/utils';",
+		"src/infrastructure/Client.ts": "import { slug } from '../shared/utils';",
+	})
+
+	cfg := config.DefaultConfig
+	if got := countKind(analyze(t, &cfg, files).FindViolations(), domain.ViolationKindLayerLeak); got != 0 {
+		t.Errorf("Expected no leak while the transitive check is disabled, got %d", got)
+	}
+
+	cfg.Transitive = config.TransitiveConfig{Enabled: true, MaxDepth: 3}
+	var leaks []domain.Violation
+	for _, v := range analyze(t, &cfg, files).FindViolations() {
+		if v.Kind == domain.ViolationKindLayerLeak {
+			leaks = append(leaks, v)
+		}
+	}
+	// The domain and the application reach the infrastructure through shared files
+	if len(leaks) != 2 {
+		t.Fatalf("Expected 2 leaks, got %v", leaks)
+	}
+	for _, v := range leaks {
+		if v.File == filepath.Join(root, "src/domain/User.ts") {
+			want := strings.Join([]string{
+				filepath.Join(root, "src/domain/User.ts"),
+				filepath.Join(root, "src/shared/utils.ts"),
+				filepath.Join(root, "src/shared/format.ts"),
+				filepath.Join(root, "src/infrastructure/S3.ts"),
+			}, " -> ")
+			if v.Target != want || v.Severity != domain.SeverityCritical || v.Line != 1 {
+				t.Errorf("Expected the leak path %q at the first import, got %+v", want, v)
+			}
+		}
+	}
+
+	cfg.Transitive.MaxDepth = 2
+	if got := countKind(analyze(t, &cfg, files).FindViolations(), domain.ViolationKindLayerLeak); got != 0 {
+		t.Errorf("Expected no leak beyond the maximum depth, got %d", got)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	root := t.TempDir()
+	files := writeFiles(t, root, map[string]string{
+		"src/domain/billing/Invoice.ts":     "export class Invoice {}",
+		"src/domain/billing/Invoices.ts":    "import { Invoice } from './Invoice';\nexport interface Invoices { save(i: Invoice): void; }",
+		"src/application/billing/Pay.ts":    "import { Invoices } from '../../domain/billing/Invoices';\nexport class Pay {}",
+		"src/infrastructure/SqlInvoices.ts": "import { Invoices } from '../domain/billing/Invoices';\nimport { Invoice } from '../domain/billing/Invoice';\nexport class SqlInvoices {}",
+	})
+	cfg := config.DefaultConfig
+	cfg.Contexts.InferLayers = []string{"domain", "application"}
+	an := analyze(t, &cfg, files)
+	an.RootDir = root
+
+	metrics := an.CalculateMetrics()
+	byName := func(groups []analysis.PackageMetrics, name string) analysis.PackageMetrics {
+		for _, g := range groups {
+			if g.Name == name {
+				return g
+			}
+		}
+		t.Fatalf("Expected metrics for %s, got %+v", name, groups)
+		return analysis.PackageMetrics{}
+	}
+
+	// The domain is imported by two files and imports nothing: stable, and half abstract
+	want := analysis.PackageMetrics{Name: "src/domain/billing", Files: 2, AfferentCoupling: 2, EfferentCoupling: 0,
+		Instability: 0, AbstractTypes: 1, ConcreteTypes: 1, Abstractness: 0.5, DistanceFromMain: 0.5}
+	if got := byName(metrics.Directories, "src/domain/billing"); got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	want = analysis.PackageMetrics{Name: "infrastructure", Files: 1, AfferentCoupling: 0, EfferentCoupling: 1,
+		Instability: 1, AbstractTypes: 0, ConcreteTypes: 1, Abstractness: 0, DistanceFromMain: 0}
+	if got := byName(metrics.Layers, "infrastructure"); got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if got := byName(metrics.Contexts, "billing"); got.Files != 3 || got.AfferentCoupling != 1 || got.EfferentCoupling != 0 {
+		t.Errorf("Expected the billing context to be imported by the infrastructure file, got %+v", got)
+	}
+}
+
+func TestLayerDetection(t *testing.T) {
+	cfg := config.DefaultConfig
+	cfg.Layers = []config.LayerDefinition{
+		{Name: "domain", Patterns: []string{"core/**"}},
+		{Name: "infrastructure", Patterns: []string{"re:/(db|queue)/"}},
+		{Name: "application", Patterns: []string{"core/usecases/**"}},
+	}
+	cfg.LayerOverrides = map[string]string{"core/legacy/Mailer.ts": "infrastructure"}
+	an := analyze(t, &cfg, map[string]string{
+		"/repo/src/core/User.ts":            "export class User {}",
+		"/repo/src/core/usecases/Signup.ts": "export class Signup {}",
+		"/repo/src/core/legacy/Mailer.ts":   "export class Mailer {}",
+		"/repo/src/queue/Consumer.ts":       "export class Consumer {}",
+		"/repo/src/web/App.ts":              "export class App {}",
+	})
+
+	tests := []struct {
+		path, layer, source string
+	}{
+		{"/repo/src/core/User.ts", "domain", "pattern:core/**"},
+		// Layers are tried in order, so the earlier core/** wins
+		{"/repo/src/core/usecases/Signup.ts", "domain", "pattern:core/**"},
+		// Overrides take precedence over the layer patterns
+		{"/repo/src/core/legacy/Mailer.ts", "infrastructure", "override:core/legacy/Mailer.ts"},
+		{"/repo/src/queue/Consumer.ts", "infrastructure", "pattern:re:/(db|queue)/"},
+		{"/repo/src/web/App.ts", "", ""},
+	}
+	for _, tt := range tests {
+		n, ok := an.Graph.GetNode(tt.path)
+		if !ok {
+			t.Fatalf("Expected a node for %s", tt.path)
+		}
+		if n.Metadata["layer"] != tt.layer || n.Metadata["layer_source"] != tt.source {
+			t.Errorf("Expected %s in layer %q from %q, got %q from %q", tt.path, tt.layer, tt.source, n.Metadata["layer"], n.Metadata["layer_source"])
+		}
+	}
+}
+
+func TestStepDefinitionsInFeaturesDir(t *testing.T) {
+	root := t.TempDir()
+	an := analyze(t, nil, writeFiles(t, root, map[string]string{
+		"e2e/features/checkout.feature": `Feature: Checkout
+  Scenario: Pay
+    Given a cart
+`,
+		"e2e/features/support/cart.ts": `Given('a cart', () => {});`,
+		// A product feature directory, not a BDD one
+		"src/features/cart/cart.ts": `Given('an empty cart', () => {});`,
+	}))
+
+	stepFiles := func() []string {
+		var files []string
+		for _, n := range an.Graph.GetAllNodes() {
+			if n.Kind == domain.NodeKindStepDefinition {
+				files = append(files, strings.TrimPrefix(n.Properties["filepath"].(string), root))
+			}
+		}
+		sort.Strings(files)
+		return files
+	}
+	if want := []string{"/e2e/features/support/cart.ts"}; !reflect.DeepEqual(stepFiles(), want) {
+		t.Errorf("Expected step definitions in %v only, got %v", want, stepFiles())
+	}
+
+	// A feature analyzed later turns the directory into a BDD one
+	if err := an.AnalyzeFile(filepath.Join(root, "src/features/cart/cart.feature"), []byte("Feature: Cart\n  Scenario: Empty\n    Given an empty cart\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := an.AnalyzeFile(filepath.Join(root, "src/features/cart/cart.ts"), []byte(`Given('an empty cart', () => {});`)); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/e2e/features/support/cart.ts", "/src/features/cart/cart.ts"}; !reflect.DeepEqual(stepFiles(), want) {
+		t.Errorf("Expected step definitions in %v, got %v", want, stepFiles())
+	}
+}
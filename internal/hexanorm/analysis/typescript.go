@@ -0,0 +1,400 @@
+package analysis
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+)
+
+// TSConfig represents a subset of tsconfig.json used for import resolution.
+type TSConfig struct {
+	BaseUrl string              `json:"baseUrl"`
+	Paths   map[string][]string `json:"paths"`
+	Extends []string            `json:"-"` // Configurations it extends, as written.
+}
+
+// PackageJSON represents a subset of the package.json of a workspace package used for import resolution.
+type PackageJSON struct {
+	Name    string      `json:"name"`
+	Main    string      `json:"main"`
+	Module  string      `json:"module"`
+	Types   string      `json:"types"`
+	Exports interface{} `json:"exports"`
+	Dir     string      `json:"-"` // Directory of the package.json.
+}
+
+// tsExtensions are the extensions probed, in order, for an import without extension.
+var tsExtensions = []string{".ts", ".tsx", ".d.ts", ".js", ".jsx", ".mjs", ".cjs", ".mts", ".cts"}
+
+// tsSourceExtensions maps JavaScript extensions to the TypeScript sources they are compiled from,
+// e.g. `import './user.js'` written in TypeScript refers to `user.ts`.
+var tsSourceExtensions = map[string][]string{
+	".js":  {".ts", ".tsx"},
+	".jsx": {".tsx"},
+	".mjs": {".mts"},
+	".cjs": {".cts"},
+}
+
+// exportConditions are the package.json `exports` conditions tried, in order.
+var exportConditions = []string{"types", "import", "module", "default", "require", "node"}
+
+// isTSConfig reports whether the file is a TypeScript or JavaScript project configuration,
+// e.g. `tsconfig.json`, `tsconfig.base.json` or `jsconfig.json`.
+func isTSConfig(path string) bool {
+	base := filepath.Base(path)
+	return base == "jsconfig.json" || strings.HasPrefix(base, "tsconfig") && strings.HasSuffix(base, ".json")
+}
+
+func (a *Analyzer) parseTSConfig(path string, content []byte) {
+	// Simplified parsing for compilerOptions.paths, baseUrl and extends
+	var raw struct {
+		Extends         json.RawMessage `json:"extends"`
+		CompilerOptions struct {
+			BaseUrl string              `json:"baseUrl"`
+			Paths   map[string][]string `json:"paths"`
+		} `json:"compilerOptions"`
+	}
+	if err := json.Unmarshal(stripJSONComments(content), &raw); err != nil {
+		return
+	}
+	cfg := TSConfig{
+		BaseUrl: raw.CompilerOptions.BaseUrl,
+		Paths:   raw.CompilerOptions.Paths,
+	}
+	// extends is a path or, since TypeScript 5.0, a list of paths
+	var single string
+	if json.Unmarshal(raw.Extends, &single) == nil {
+		cfg.Extends = []string{single}
+	} else {
+		json.Unmarshal(raw.Extends, &cfg.Extends)
+	}
+	a.tsConfigs[path] = cfg
+}
+
+func (a *Analyzer) parsePackageJSON(path string, content []byte) {
+	var pkg PackageJSON
+	if err := json.Unmarshal(content, &pkg); err != nil || pkg.Name == "" {
+		return
+	}
+	pkg.Dir = filepath.Dir(path)
+	a.packages[pkg.Name] = pkg
+}
+
+// tsResolution holds the module resolution settings in effect for a file, with absolute directories.
+type tsResolution struct {
+	baseUrl   string              // Directory non-relative imports are resolved from, if set.
+	paths     map[string][]string // Path mappings.
+	pathsBase string              // Directory the path mappings are relative to.
+}
+
+// resolveTSImport resolves a JavaScript or TypeScript import like Node and the TypeScript compiler do:
+// relative imports, then the `paths` and `baseUrl` of the nearest tsconfig (following `extends`),
+// then workspace packages by their package.json `name` and `exports`.
+// Each candidate is probed with the TypeScript and JavaScript extensions and as a directory with an `index` file.
+// Imports that cannot be resolved, such as external packages, are returned as written.
+func (a *Analyzer) resolveTSImport(sourcePath, importStr string) string {
+	// 1. Relative
+	if strings.HasPrefix(importStr, ".") || filepath.IsAbs(importStr) {
+		target := importStr
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(sourcePath), importStr)
+		}
+		if resolved := a.probeModule(target); resolved != "" {
+			return resolved
+		}
+		return target
+	}
+
+	// 2. TSConfig paths and baseUrl
+	if res, ok := a.nearestTSConfig(sourcePath); ok {
+		for _, target := range matchTSPaths(res.paths, importStr) {
+			if resolved := a.probeModule(filepath.Join(res.pathsBase, target)); resolved != "" {
+				return resolved
+			}
+		}
+		if res.baseUrl != "" {
+			if resolved := a.probeModule(filepath.Join(res.baseUrl, importStr)); resolved != "" {
+				return resolved
+			}
+		}
+	}
+
+	// 3. Workspace packages
+	if resolved := a.resolveWorkspaceImport(importStr); resolved != "" {
+		return resolved
+	}
+
+	return importStr
+}
+
+// nearestTSConfig returns the resolution settings of the closest tsconfig.json (or jsconfig.json) above the file.
+func (a *Analyzer) nearestTSConfig(sourcePath string) (tsResolution, bool) {
+	dir := filepath.Dir(sourcePath)
+	for {
+		for _, name := range []string{"tsconfig.json", "jsconfig.json"} {
+			path := filepath.Join(dir, name)
+			if _, ok := a.tsConfigs[path]; ok {
+				res := a.effectiveTSConfig(path, make(map[string]bool))
+				if res.baseUrl != "" {
+					// Paths are relative to baseUrl when it is set
+					res.pathsBase = res.baseUrl
+				}
+				return res, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return tsResolution{}, false
+		}
+		dir = parent
+	}
+}
+
+// effectiveTSConfig merges a tsconfig with the configurations it extends. Settings are resolved relative
+// to the file declaring them, and the extending file overrides the extended ones.
+func (a *Analyzer) effectiveTSConfig(path string, seen map[string]bool) tsResolution {
+	cfg, ok := a.tsConfigs[path]
+	if !ok || seen[path] {
+		return tsResolution{}
+	}
+	seen[path] = true
+
+	dir := filepath.Dir(path)
+	var res tsResolution
+	for _, ext := range cfg.Extends {
+		// Only extended files of the project are known, not those of installed packages
+		if !strings.HasPrefix(ext, ".") && !filepath.IsAbs(ext) {
+			continue
+		}
+		parent := filepath.Join(dir, ext)
+		if filepath.Ext(parent) != ".json" {
+			parent += ".json"
+		}
+		base := a.effectiveTSConfig(parent, seen)
+		if base.baseUrl != "" {
+			res.baseUrl = base.baseUrl
+		}
+		if base.paths != nil {
+			res.paths, res.pathsBase = base.paths, base.pathsBase
+		}
+	}
+	if cfg.BaseUrl != "" {
+		res.baseUrl = filepath.Join(dir, cfg.BaseUrl)
+	}
+	if cfg.Paths != nil {
+		res.paths, res.pathsBase = cfg.Paths, dir
+	}
+	return res
+}
+
+// matchTSPaths returns the targets of the `paths` mapping matching the import, with the wildcard substituted.
+// An exact pattern wins over wildcards, and among wildcards the one with the longest prefix wins.
+func matchTSPaths(paths map[string][]string, importStr string) []string {
+	if targets, ok := paths[importStr]; ok {
+		return targets
+	}
+	best, bestMatch, bestLen := "", "", -1
+	for pattern := range paths {
+		prefix, suffix, ok := strings.Cut(pattern, "*")
+		if !ok || len(prefix) <= bestLen || !strings.HasPrefix(importStr, prefix) || !strings.HasSuffix(importStr, suffix) || len(importStr) < len(prefix)+len(suffix) {
+			continue
+		}
+		best, bestMatch, bestLen = pattern, importStr[len(prefix):len(importStr)-len(suffix)], len(prefix)
+	}
+	if bestLen < 0 {
+		return nil
+	}
+	var targets []string
+	for _, t := range paths[best] {
+		targets = append(targets, strings.Replace(t, "*", bestMatch, 1))
+	}
+	return targets
+}
+
+// resolveWorkspaceImport resolves an import of a workspace package, e.g. `@acme/domain` or `@acme/domain/user`,
+// through its package.json `exports`, or its `types`, `module` and `main` entries.
+func (a *Analyzer) resolveWorkspaceImport(importStr string) string {
+	var pkg PackageJSON
+	found := false
+	for name, p := range a.packages {
+		if (importStr == name || strings.HasPrefix(importStr, name+"/")) && (!found || len(name) > len(pkg.Name)) {
+			pkg, found = p, true
+		}
+	}
+	if !found {
+		return ""
+	}
+
+	subpath := "." + strings.TrimPrefix(importStr, pkg.Name)
+	var targets []string
+	if pkg.Exports != nil {
+		targets = exportTargets(pkg.Exports, subpath)
+	} else if subpath == "." {
+		targets = []string{pkg.Types, pkg.Module, pkg.Main, "index"}
+	} else {
+		targets = []string{subpath}
+	}
+
+	var first string
+	for _, t := range targets {
+		if t == "" {
+			continue
+		}
+		target := filepath.Join(pkg.Dir, t)
+		if resolved := a.probeModule(target); resolved != "" {
+			return resolved
+		}
+		if first == "" {
+			first = target
+		}
+	}
+	return first
+}
+
+// exportTargets returns the targets a package.json `exports` field maps the subpath (`.` or `./user`) to,
+// in order of preference.
+func exportTargets(exports interface{}, subpath string) []string {
+	m, ok := exports.(map[string]interface{})
+	if !ok || !hasSubpathKeys(m) {
+		// A single target or conditions, for the package root only
+		if subpath != "." {
+			return nil
+		}
+		return conditionTargets(exports, "")
+	}
+
+	if target, ok := m[subpath]; ok {
+		return conditionTargets(target, "")
+	}
+	// Subpath patterns, e.g. "./*": "./src/*.ts"; the longest prefix wins
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+	for _, k := range keys {
+		prefix, suffix, ok := strings.Cut(k, "*")
+		if ok && strings.HasPrefix(subpath, prefix) && strings.HasSuffix(subpath, suffix) && len(subpath) >= len(prefix)+len(suffix) {
+			return conditionTargets(m[k], subpath[len(prefix):len(subpath)-len(suffix)])
+		}
+	}
+	return nil
+}
+
+// hasSubpathKeys reports whether an `exports` object maps subpaths (keys starting with `.`) rather than conditions.
+func hasSubpathKeys(m map[string]interface{}) bool {
+	for k := range m {
+		if strings.HasPrefix(k, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionTargets flattens an `exports` target, a path, a list of fallbacks or nested conditions,
+// into paths with the pattern wildcard substituted.
+func conditionTargets(target interface{}, wildcard string) []string {
+	switch t := target.(type) {
+	case string:
+		return []string{strings.ReplaceAll(t, "*", wildcard)}
+	case []interface{}:
+		var targets []string
+		for _, alt := range t {
+			targets = append(targets, conditionTargets(alt, wildcard)...)
+		}
+		return targets
+	case map[string]interface{}:
+		var targets []string
+		for _, cond := range exportConditions {
+			if v, ok := t[cond]; ok {
+				targets = append(targets, conditionTargets(v, wildcard)...)
+			}
+		}
+		return targets
+	}
+	return nil
+}
+
+// probeModule returns the file a module path refers to: the path itself, its TypeScript source for a `.js` path,
+// the path with a TypeScript or JavaScript extension, or the `index` file of the directory. It returns "" if none exists.
+func (a *Analyzer) probeModule(path string) string {
+	if a.isFile(path) {
+		return path
+	}
+	ext := filepath.Ext(path)
+	for _, src := range tsSourceExtensions[ext] {
+		if candidate := strings.TrimSuffix(path, ext) + src; a.isFile(candidate) {
+			return candidate
+		}
+	}
+	for _, ext := range tsExtensions {
+		if a.isFile(path + ext) {
+			return path + ext
+		}
+	}
+	for _, ext := range tsExtensions {
+		if index := filepath.Join(path, "index"+ext); a.isFile(index) {
+			return index
+		}
+	}
+	return ""
+}
+
+// isFile reports whether the path is an analyzed code file, or a file on disk not analyzed yet.
+func (a *Analyzer) isFile(path string) bool {
+	if n, ok := a.Graph.GetNode(path); ok {
+		return n.Kind == domain.NodeKindCode
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// stripJSONComments removes the comments and trailing commas that tsconfig files allow but JSON does not.
+func stripJSONComments(content []byte) []byte {
+	var out []byte
+	inString := false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(content) {
+				i++
+				out = append(out, content[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(content) && content[i+1] == '/':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			i--
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			i += 2
+			for i+1 < len(content) && !(content[i] == '*' && content[i+1] == '/') {
+				i++
+			}
+			i++
+		case c == '}' || c == ']':
+			// Drop a trailing comma before the closing bracket
+			j := len(out) - 1
+			for j >= 0 && (out[j] == ' ' || out[j] == '\t' || out[j] == '\n' || out[j] == '\r') {
+				j--
+			}
+			if j >= 0 && out[j] == ',' {
+				out = append(out[:j], out[j+1:]...)
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -2,16 +2,110 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
 )
 
 // Config represents the configuration for the Hexanorm server.
 // It controls directory exclusion, layer inclusion, and persistence settings.
 type Config struct {
-	ExcludedDirs   []string `json:"excluded_dirs"`   // List of directory names to exclude from analysis.
-	IncludedLayers []string `json:"included_layers"` // List of architectural layers to analyze.
-	PersistenceDir string   `json:"persistence_dir"` // Directory path to store the SQLite database.
+	ExcludedDirs   []string                 `json:"excluded_dirs"`   // List of directory names to exclude from analysis.
+	IncludedLayers []string                 `json:"included_layers"` // List of architectural layers to analyze, by default those of Layers.
+	PersistenceDir string                   `json:"persistence_dir"` // Directory path to store the SQLite database.
+	LayerRules     []LayerRule              `json:"layer_rules"`     // Allowed-dependency matrix between layers.
+	ExternalRules  []ExternalRule           `json:"external_rules"`  // External packages each layer may or may not import.
+	Transitive     TransitiveConfig         `json:"transitive"`      // Detection of layer rules broken through unlayered files.
+	Layers         []LayerDefinition        `json:"layers"`          // Ordered path patterns used to detect each layer.
+	LayerOverrides map[string]string        `json:"layer_overrides"` // Per-file layer overrides, keyed by path pattern.
+	Contexts       ContextConfig            `json:"contexts"`        // Bounded-context detection and isolation rules.
+	CycleSeverity  domain.ViolationSeverity `json:"cycle_severity"`  // Severity reported for import cycles.
+	Ports          PortsConfig              `json:"ports"`           // Ports-and-adapters conformance rules.
+	Requirements   RequirementsConfig       `json:"requirements"`    // How requirements are discovered.
+	BaselineFile   string                   `json:"baseline_file"`   // Baseline of accepted violations, relative to the root.
+}
+
+// LayerDefinition maps an architectural layer to the path patterns of its files.
+// Layers are tried in order and the first matching pattern wins.
+// See CompilePattern for the pattern syntax.
+type LayerDefinition struct {
+	Name     string   `json:"name"`     // The name of the layer, as used in LayerRules.
+	Patterns []string `json:"patterns"` // Glob or "re:"-prefixed regex patterns matching the layer's files.
+}
+
+// LayerRule declares which layers code in a given layer is allowed to import.
+// Imports inside the same layer are always allowed. A layer without a rule is unrestricted.
+// A rule whose Layer is "*" applies to every layer, e.g. to let everyone import a "shared" layer.
+type LayerRule struct {
+	Layer     string                   `json:"layer"`      // The importing layer, or "*" for every layer.
+	MayImport []string                 `json:"may_import"` // Layers that may be imported. "*" allows any layer.
+	Severity  domain.ViolationSeverity `json:"severity"`   // Severity reported when the rule is broken.
+}
+
+// ExternalRule declares which external packages, i.e. imports resolving to no file of the project, code in a
+// given layer may import. A package is forbidden when it matches Forbid, or when Allow is set and the package
+// matches none of its patterns. Every rule of the layer, including "*" rules, must allow the package, and a
+// layer without a rule may import any package.
+// A pattern matches a package and its sub-packages, e.g. `github.com/aws` matches `github.com/aws/aws-sdk-go/s3`,
+// a trailing `*` matches any suffix, and `stdlib` matches the standard library of the importing language.
+type ExternalRule struct {
+	Layer    string                   `json:"layer"`    // The importing layer, or "*" for every layer.
+	Allow    []string                 `json:"allow"`    // Package patterns the layer may import. Empty allows any package.
+	Forbid   []string                 `json:"forbid"`   // Package patterns the layer may not import.
+	Severity domain.ViolationSeverity `json:"severity"` // Severity reported when the rule is broken.
+}
+
+// TransitiveConfig controls the detection of layer leaks: a layered file reaching a layer its LayerRules forbid
+// through a chain of imports of unlayered files, e.g. `domain/User.ts -> shared/utils.ts -> infrastructure/Db.ts`.
+type TransitiveConfig struct {
+	Enabled  bool `json:"enabled"`   // Whether to follow import chains through unlayered files.
+	MaxDepth int  `json:"max_depth"` // Maximum number of imports in a chain.
+}
+
+// ContextConfig controls how bounded contexts are detected and which cross-context imports are allowed.
+// Contexts are taken from the declared Definitions first, then from the `(?P<context>...)` group of regex
+// layer patterns. Inference from the path segment after the layer directory, e.g. `src/domain/billing/Invoice.ts`
+// belonging to `billing`, is opt-in: it only applies to the InferLayers.
+type ContextConfig struct {
+	DisableInference bool                     `json:"disable_inference"` // Only use the declared Definitions.
+	InferLayers      []string                 `json:"infer_layers"`      // Layers whose sub-directories are inferred as contexts, none by default.
+	Definitions      []ContextDefinition      `json:"definitions"`       // Explicitly declared bounded contexts.
+	SharedKernel     []string                 `json:"shared_kernel"`     // Contexts that every other context may import.
+	PublicAPI        []string                 `json:"public_api"`        // Path patterns any context may import from another context.
+	Severity         domain.ViolationSeverity `json:"severity"`          // Severity reported for cross-context imports.
+}
+
+// ContextDefinition declares a bounded context by the path patterns of its files.
+type ContextDefinition struct {
+	Name      string   `json:"name"`       // The name of the bounded context.
+	Patterns  []string `json:"patterns"`   // Path patterns matching the context's files.
+	PublicAPI []string `json:"public_api"` // Path patterns of the files other contexts may import.
+}
+
+// PortsConfig controls how ports and adapters are recognized.
+// Ports are interfaces (or traits and abstract classes) declared in the PortLayers or in files matching
+// PortPatterns. Adapters are concrete types with methods declared in the AdapterLayers that implement or extend
+// a type, implement a port structurally (Go), or are declared in files matching AdapterPatterns. Other types of
+// the AdapterLayers, such as DTOs and mappers, are not adapters.
+type PortsConfig struct {
+	PortLayers      []string                 `json:"port_layers"`      // Layers whose interfaces are ports.
+	PortPatterns    []string                 `json:"port_patterns"`    // Path patterns whose interfaces are ports in any layer.
+	AdapterLayers   []string                 `json:"adapter_layers"`   // Layers whose concrete types may be adapters.
+	AdapterPatterns []string                 `json:"adapter_patterns"` // Path patterns whose concrete types of the AdapterLayers are adapters.
+	ConsumerLayers  []string                 `json:"consumer_layers"`  // Layers that must depend on ports instead of adapters.
+	Severity        domain.ViolationSeverity `json:"severity"`         // Severity reported for conformance violations.
+}
+
+// RequirementsConfig controls how requirements are discovered.
+// Gherkin tags matching TagPattern, e.g. `@REQ-123`, reference the requirement whose ID is the
+// pattern's first capture group (or the whole tag without `@` if the pattern has no group).
+// Markdown and YAML files under Dirs declare requirements as code.
+type RequirementsConfig struct {
+	TagPattern string   `json:"tag_pattern"` // Regex matching the Gherkin tags that reference requirements.
+	Dirs       []string `json:"dirs"`        // Directories holding requirement files, as path patterns.
 }
 
 // DefaultConfig provides a standard configuration used when no config file is found.
@@ -19,6 +113,37 @@ var DefaultConfig = Config{
 	ExcludedDirs:   []string{"node_modules", "dist", "build", ".git", "vendor"},
 	IncludedLayers: []string{"domain", "application", "infrastructure", "interface"},
 	PersistenceDir: ".hexanorm",
+	// The domain may not import application or infrastructure code, the application may not import infrastructure
+	LayerRules: []LayerRule{
+		{Layer: "domain", MayImport: []string{"interface"}, Severity: domain.SeverityCritical},
+		{Layer: "application", MayImport: []string{"domain", "interface"}, Severity: domain.SeverityWarning},
+	},
+	Layers: []LayerDefinition{
+		{Name: "domain", Patterns: []string{"domain/**"}},
+		{Name: "application", Patterns: []string{"application/**"}},
+		{Name: "infrastructure", Patterns: []string{"infrastructure/**"}},
+		{Name: "interface", Patterns: []string{"interface/**", "api/**"}},
+	},
+	Contexts: ContextConfig{
+		Severity: domain.SeverityWarning,
+	},
+	Transitive: TransitiveConfig{
+		MaxDepth: 5,
+	},
+	CycleSeverity: domain.SeverityWarning,
+	Ports: PortsConfig{
+		PortLayers:      []string{"domain", "application"},
+		PortPatterns:    []string{"ports/**"},
+		AdapterLayers:   []string{"infrastructure"},
+		AdapterPatterns: []string{"adapters/**"},
+		ConsumerLayers:  []string{"application"},
+		Severity:        domain.SeverityWarning,
+	},
+	Requirements: RequirementsConfig{
+		TagPattern: `^@(REQ-[0-9]+)$`,
+		Dirs:       []string{"docs/requirements"},
+	},
+	BaselineFile: "hexanorm-baseline.json",
 }
 
 // LoadConfig reads and parses the `hexanorm.json` configuration file from the specified root directory.
@@ -36,17 +161,127 @@ func LoadConfig(rootDir string) (*Config, error) {
 	if err := json.Unmarshal(content, &cfg); err != nil {
 		return nil, err
 	}
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(content, &keys); err != nil {
+		return nil, err
+	}
 
 	// Apply defaults if empty
 	if len(cfg.ExcludedDirs) == 0 {
 		cfg.ExcludedDirs = DefaultConfig.ExcludedDirs
 	}
-	if len(cfg.IncludedLayers) == 0 {
-		cfg.IncludedLayers = DefaultConfig.IncludedLayers
-	}
 	if cfg.PersistenceDir == "" {
 		cfg.PersistenceDir = DefaultConfig.PersistenceDir
 	}
+	// An explicit empty matrix allows every import
+	if _, ok := keys["layer_rules"]; !ok {
+		cfg.LayerRules = DefaultConfig.LayerRules
+	}
+	for i := range cfg.LayerRules {
+		if cfg.LayerRules[i].Severity == "" {
+			cfg.LayerRules[i].Severity = domain.SeverityWarning
+		}
+	}
+	for i := range cfg.ExternalRules {
+		if cfg.ExternalRules[i].Severity == "" {
+			cfg.ExternalRules[i].Severity = domain.SeverityWarning
+		}
+	}
+	if cfg.Transitive.MaxDepth <= 0 {
+		cfg.Transitive.MaxDepth = DefaultConfig.Transitive.MaxDepth
+	}
+	if len(cfg.Layers) == 0 {
+		cfg.Layers = DefaultConfig.Layers
+	}
+	// Every declared layer is analyzed unless the included layers are listed explicitly
+	if len(cfg.IncludedLayers) == 0 {
+		seen := make(map[string]bool)
+		for _, l := range cfg.Layers {
+			if !seen[l.Name] {
+				seen[l.Name] = true
+				cfg.IncludedLayers = append(cfg.IncludedLayers, l.Name)
+			}
+		}
+	}
+	if cfg.Contexts.Severity == "" {
+		cfg.Contexts.Severity = DefaultConfig.Contexts.Severity
+	}
+	if cfg.CycleSeverity == "" {
+		cfg.CycleSeverity = DefaultConfig.CycleSeverity
+	}
+	if len(cfg.Ports.PortLayers) == 0 {
+		cfg.Ports.PortLayers = DefaultConfig.Ports.PortLayers
+	}
+	if len(cfg.Ports.PortPatterns) == 0 {
+		cfg.Ports.PortPatterns = DefaultConfig.Ports.PortPatterns
+	}
+	if len(cfg.Ports.AdapterLayers) == 0 {
+		cfg.Ports.AdapterLayers = DefaultConfig.Ports.AdapterLayers
+	}
+	if len(cfg.Ports.AdapterPatterns) == 0 {
+		cfg.Ports.AdapterPatterns = DefaultConfig.Ports.AdapterPatterns
+	}
+	if len(cfg.Ports.ConsumerLayers) == 0 {
+		cfg.Ports.ConsumerLayers = DefaultConfig.Ports.ConsumerLayers
+	}
+	if cfg.Ports.Severity == "" {
+		cfg.Ports.Severity = DefaultConfig.Ports.Severity
+	}
+	if cfg.Requirements.TagPattern == "" {
+		cfg.Requirements.TagPattern = DefaultConfig.Requirements.TagPattern
+	}
+	if cfg.BaselineFile == "" {
+		cfg.BaselineFile = DefaultConfig.BaselineFile
+	}
+	if len(cfg.Requirements.Dirs) == 0 {
+		cfg.Requirements.Dirs = DefaultConfig.Requirements.Dirs
+	}
+
+	if err := cfg.validatePatterns(); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
+
+// validatePatterns checks that every path pattern in the configuration compiles.
+func (c *Config) validatePatterns() error {
+	for _, l := range c.Layers {
+		for _, p := range l.Patterns {
+			if _, err := CompilePattern(p); err != nil {
+				return fmt.Errorf("invalid pattern %q for layer %s: %w", p, l.Name, err)
+			}
+		}
+	}
+	for p := range c.LayerOverrides {
+		if _, err := CompilePattern(p); err != nil {
+			return fmt.Errorf("invalid layer override pattern %q: %w", p, err)
+		}
+	}
+	for _, p := range c.Contexts.PublicAPI {
+		if _, err := CompilePattern(p); err != nil {
+			return fmt.Errorf("invalid public API pattern %q: %w", p, err)
+		}
+	}
+	for _, p := range c.Ports.PortPatterns {
+		if _, err := CompilePattern(p); err != nil {
+			return fmt.Errorf("invalid port pattern %q: %w", p, err)
+		}
+	}
+	for _, p := range c.Requirements.Dirs {
+		if _, err := CompilePattern(p + "/**"); err != nil {
+			return fmt.Errorf("invalid requirements directory %q: %w", p, err)
+		}
+	}
+	if _, err := regexp.Compile(c.Requirements.TagPattern); err != nil {
+		return fmt.Errorf("invalid requirement tag pattern %q: %w", c.Requirements.TagPattern, err)
+	}
+	for _, d := range c.Contexts.Definitions {
+		for _, p := range append(d.Patterns, d.PublicAPI...) {
+			if _, err := CompilePattern(p); err != nil {
+				return fmt.Errorf("invalid pattern %q for context %s: %w", p, d.Name, err)
+			}
+		}
+	}
+	return nil
+}
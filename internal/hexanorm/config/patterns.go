@@ -0,0 +1,55 @@
+package config
+
+import (
+	"regexp"
+	"strings"
+)
+
+// regexPrefix marks a path pattern as a regular expression instead of a glob.
+const regexPrefix = "re:"
+
+// Names of the capture groups used to infer bounded contexts from layer patterns.
+const (
+	RestGroup    = "rest"    // The part of the path after a glob's trailing `/**`.
+	ContextGroup = "context" // The bounded context, for regex patterns that capture it explicitly.
+)
+
+// CompilePattern compiles a path pattern from the configuration into a regular expression.
+// Patterns prefixed with "re:" are used as regular expressions as-is.
+// Any other pattern is a glob where `*` matches within a path segment, `?` matches a single
+// character and `**` matches across segments. Globs are matched against the trailing part of
+// a path starting at a directory boundary, so `core/**` matches `/repo/src/core/user.go`.
+// A trailing `/**` captures the rest of the path in the RestGroup.
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, regexPrefix) {
+		return regexp.Compile(strings.TrimPrefix(pattern, regexPrefix))
+	}
+	glob := strings.TrimPrefix(pattern, "/")
+	if strings.HasSuffix(glob, "/**") {
+		return regexp.Compile("(^|/)" + globToRegex(strings.TrimSuffix(glob, "/**")) + "/(?P<" + RestGroup + ">.*)$")
+	}
+	return regexp.Compile("(^|/)" + globToRegex(glob) + "$")
+}
+
+// globToRegex translates glob syntax into an unanchored regular expression.
+func globToRegex(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
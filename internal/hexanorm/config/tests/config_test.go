@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/config"
+)
+
+// loadConfig writes a hexanorm.json to a temporary directory and loads it.
+func loadConfig(t *testing.T, content string) *config.Config {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hexanorm.json"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	return cfg
+}
+
+func TestLoadConfigIncludedLayers(t *testing.T) {
+	cfg := loadConfig(t, `{
+  "layers": [
+    { "name": "core", "patterns": ["core/**"] },
+    { "name": "usecases", "patterns": ["usecases/**"] },
+    { "name": "core", "patterns": ["kernel/**"] }
+  ]
+}`)
+	if want := []string{"core", "usecases"}; !reflect.DeepEqual(cfg.IncludedLayers, want) {
+		t.Errorf("Expected the declared layers to be included, got %v", cfg.IncludedLayers)
+	}
+
+	cfg = loadConfig(t, `{
+  "included_layers": ["core"],
+  "layers": [{ "name": "core", "patterns": ["core/**"] }, { "name": "usecases", "patterns": ["usecases/**"] }]
+}`)
+	if want := []string{"core"}; !reflect.DeepEqual(cfg.IncludedLayers, want) {
+		t.Errorf("Expected the explicit included layers to be kept, got %v", cfg.IncludedLayers)
+	}
+
+	cfg = loadConfig(t, `{}`)
+	if !reflect.DeepEqual(cfg.IncludedLayers, config.DefaultConfig.IncludedLayers) {
+		t.Errorf("Expected the default layers to be included, got %v", cfg.IncludedLayers)
+	}
+}
+
+func TestLoadConfigLayerRules(t *testing.T) {
+	cfg := loadConfig(t, `{}`)
+	if !reflect.DeepEqual(cfg.LayerRules, config.DefaultConfig.LayerRules) {
+		t.Errorf("Expected the default layer rules without layer_rules, got %v", cfg.LayerRules)
+	}
+
+	cfg = loadConfig(t, `{ "layer_rules": [] }`)
+	if len(cfg.LayerRules) != 0 {
+		t.Errorf("Expected an explicit empty matrix to be kept, got %v", cfg.LayerRules)
+	}
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/config"
+)
+
+func TestCompilePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		want    bool
+		rest    string
+	}{
+		{name: "glob at a directory boundary", pattern: "core/**", path: "/repo/src/core/user.go", want: true, rest: "user.go"},
+		{name: "glob inside a segment", pattern: "core/**", path: "/repo/src/hardcore/user.go", want: false},
+		{name: "leading slash", pattern: "/core/**", path: "/repo/core/user/User.ts", want: true, rest: "user/User.ts"},
+		{name: "star within a segment", pattern: "pkg/*/domain/**", path: "/repo/pkg/billing/domain/Invoice.ts", want: true, rest: "Invoice.ts"},
+		{name: "star does not cross segments", pattern: "pkg/*/domain/**", path: "/repo/pkg/billing/v2/domain/Invoice.ts", want: false},
+		{name: "double star crosses segments", pattern: "pkg/**/domain/**", path: "/repo/pkg/billing/v2/domain/Invoice.ts", want: true, rest: "Invoice.ts"},
+		{name: "double star matches no segment", pattern: "pkg/**/domain/**", path: "/repo/pkg/domain/Invoice.ts", want: true, rest: "Invoice.ts"},
+		{name: "question mark", pattern: "v?/api/**", path: "/repo/v2/api/users.go", want: true, rest: "users.go"},
+		{name: "file glob", pattern: "core/legacy/Mailer.ts", path: "/repo/src/core/legacy/Mailer.ts", want: true},
+		{name: "file glob is anchored at the end", pattern: "core/legacy/Mailer.ts", path: "/repo/src/core/legacy/Mailer.ts.bak", want: false},
+		{name: "dots are literal", pattern: "*.gen.ts", path: "/repo/src/api.genXts", want: false},
+		{name: "regex", pattern: "re:/(db|queue)/", path: "/repo/src/queue/Consumer.ts", want: true},
+		{name: "regex without match", pattern: "re:/(db|queue)/", path: "/repo/src/cache/Redis.ts", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := config.CompilePattern(tt.pattern)
+			if err != nil {
+				t.Fatalf("CompilePattern(%q) failed: %v", tt.pattern, err)
+			}
+			sub := re.FindStringSubmatch(tt.path)
+			if got := sub != nil; got != tt.want {
+				t.Fatalf("Expected %q to match %q: %v, got %v", tt.pattern, tt.path, tt.want, got)
+			}
+			if i := re.SubexpIndex(config.RestGroup); tt.rest != "" && (i < 0 || sub[i] != tt.rest) {
+				t.Errorf("Expected %q to capture the rest %q, got %q", tt.pattern, tt.rest, sub)
+			}
+		})
+	}
+}
+
+func TestCompilePatternInvalidRegex(t *testing.T) {
+	if _, err := config.CompilePattern("re:(unclosed"); err == nil {
+		t.Error("Expected an invalid regex pattern to fail")
+	}
+}
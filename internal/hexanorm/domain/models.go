@@ -12,6 +12,11 @@ const (
 	NodeKindGherkinFeature  NodeKind = "GherkinFeature"  // Represents a Gherkin .feature file.
 	NodeKindGherkinScenario NodeKind = "GherkinScenario" // Represents a single Scenario in a Gherkin file.
 	NodeKindStepDefinition  NodeKind = "StepDefinition"  // Represents a code function implementing a Gherkin step.
+	NodeKindPort            NodeKind = "Port"            // Represents an interface declared by the domain or application.
+	NodeKindAdapter         NodeKind = "Adapter"         // Represents an infrastructure type implementing ports.
+	NodeKindSymbol          NodeKind = "Symbol"          // Represents a type, function or method declared in a code file.
+	NodeKindPackage         NodeKind = "Package"         // Represents a Go package: the directory containing its code files.
+	NodeKindExternal        NodeKind = "External"        // Represents a third-party or standard library package, imported as written.
 )
 
 // EdgeType represents the relationship type between two nodes.
@@ -21,11 +26,13 @@ type EdgeType string
 const (
 	EdgeTypeDefines       EdgeType = "DEFINES"        // Requirement -> Feature
 	EdgeTypeImplementedBy EdgeType = "IMPLEMENTED_BY" // Feature -> Code, Requirement -> Code
-	EdgeTypeVerifies      EdgeType = "VERIFIES"       // Test/Scenario -> Requirement
+	EdgeTypeVerifies      EdgeType = "VERIFIES"       // Test/Scenario -> Requirement, Test -> Code/Package/Symbol
 	EdgeTypeExecutes      EdgeType = "EXECUTES"       // GherkinScenario -> StepDefinition
-	EdgeTypeCalls         EdgeType = "CALLS"          // StepDefinition -> Code
+	EdgeTypeCalls         EdgeType = "CALLS"          // StepDefinition -> Code, StepDefinition/Symbol -> Symbol
 	EdgeTypeDescribedBy   EdgeType = "DESCRIBED_BY"   // Requirement -> GherkinFeature
-	EdgeTypeImports       EdgeType = "IMPORTS"        // Code -> Code (for architectural analysis)
+	EdgeTypeImports       EdgeType = "IMPORTS"        // Code -> Code, Code -> Package, Code -> External (for architectural analysis)
+	EdgeTypeImplements    EdgeType = "IMPLEMENTS"     // Adapter -> Port
+	EdgeTypeContains      EdgeType = "CONTAINS"       // Package -> Code, Code -> Symbol, Symbol -> Symbol (type -> method)
 )
 
 // Node represents a single entity in the semantic graph.
@@ -58,17 +65,33 @@ type ViolationKind string
 
 // Constants for violation kinds.
 const (
-	ViolationKindArchLayer ViolationKind = "ARCH_LAYER_VIOLATION" // Violation of architectural layering rules.
-	ViolationKindBDDDrift  ViolationKind = "BDD_DRIFT"            // Mismatch between Gherkin specs and implementation.
+	ViolationKindArchLayer          ViolationKind = "ARCH_LAYER_VIOLATION"      // Violation of architectural layering rules.
+	ViolationKindBDDDrift           ViolationKind = "BDD_DRIFT"                 // Mismatch between Gherkin specs and implementation.
+	ViolationKindArchContext        ViolationKind = "ARCH_CONTEXT_VIOLATION"    // Import of another bounded context's internals.
+	ViolationKindImportCycle        ViolationKind = "ARCH_IMPORT_CYCLE"         // Cyclic dependency between files, packages or contexts.
+	ViolationKindPortWithoutAdapter ViolationKind = "ARCH_PORT_WITHOUT_ADAPTER" // Port that no adapter implements.
+	ViolationKindAdapterWithoutPort ViolationKind = "ARCH_ADAPTER_WITHOUT_PORT" // Adapter that implements no port.
+	ViolationKindAdapterDirectUse   ViolationKind = "ARCH_ADAPTER_DIRECT_USE"   // Application code importing a concrete adapter.
+	ViolationKindInternalImport     ViolationKind = "ARCH_INTERNAL_IMPORT"      // Go import of an internal package from outside its parent tree.
+	ViolationKindExternalImport     ViolationKind = "ARCH_EXTERNAL_IMPORT"      // Import of an external package the layer may not depend on.
+	ViolationKindLayerLeak          ViolationKind = "ARCH_LAYER_LEAK"           // Layer rule broken through a chain of imports of unlayered files.
 )
 
 // Violation represents a detected issue in the codebase, such as an architectural breach or missing test coverage.
 type Violation struct {
-	Severity ViolationSeverity `json:"severity"`       // The severity of the violation.
-	Message  string            `json:"message"`        // Human-readable description of the violation.
-	File     string            `json:"file"`           // The file associated with the violation.
-	Kind     ViolationKind     `json:"kind"`           // The category of the violation.
-	Line     int               `json:"line,omitempty"` // The line number where the violation occurred (optional).
+	Severity    ViolationSeverity `json:"severity"`              // The severity of the violation.
+	Message     string            `json:"message"`               // Human-readable description of the violation.
+	File        string            `json:"file"`                  // The file associated with the violation.
+	Target      string            `json:"target,omitempty"`      // What the file breaks the rule with, e.g. the imported file or the missing step.
+	Kind        ViolationKind     `json:"kind"`                  // The category of the violation.
+	Line        int               `json:"line,omitempty"`        // The line number where the violation occurred (optional).
+	Column      int               `json:"column,omitempty"`      // The column where the violation starts, 1-based (optional).
+	EndLine     int               `json:"end_line,omitempty"`    // The line number where the violation ends (optional).
+	EndColumn   int               `json:"end_column,omitempty"`  // The column after the end of the violation (optional).
+	RuleID      string            `json:"rule_id,omitempty"`     // Stable ID of the broken rule, e.g. `layer/domain`.
+	Remediation string            `json:"remediation,omitempty"` // Suggested fix.
+	Fingerprint string            `json:"fingerprint,omitempty"` // Stable identity of the violation: a hash of its kind, file and target.
+	Baselined   bool              `json:"baselined,omitempty"`   // Whether the violation is accepted by the baseline.
 }
 
 // Helper structs for specific node properties (optional, for type safety if needed)
@@ -84,6 +84,7 @@ func ExportExcalidraw(g *graph.Graph, outputPath string) error {
 		"application":    {},
 		"infrastructure": {},
 		"interface":      {},
+		"external":       {},
 		"other":          {},
 	}
 
@@ -93,9 +94,15 @@ func ExportExcalidraw(g *graph.Graph, outputPath string) error {
 	rectOrder := []string{}
 
 	for _, n := range nodes {
+		// Symbols would clutter the diagram, files are drawn instead
+		if n.Kind == domain.NodeKindSymbol {
+			continue
+		}
 		layer := "other"
 		if l, ok := n.Metadata["layer"].(string); ok {
 			layer = l
+		} else if n.Kind == domain.NodeKindExternal {
+			layer = "external"
 		}
 		if _, ok := layers[layer]; !ok {
 			layer = "other"
@@ -104,7 +111,7 @@ func ExportExcalidraw(g *graph.Graph, outputPath string) error {
 	}
 
 	// Sort layers for deterministic output
-	layerOrder := []string{"domain", "application", "interface", "infrastructure", "other"}
+	layerOrder := []string{"domain", "application", "interface", "infrastructure", "external", "other"}
 
 	currentY := 0.0
 
@@ -136,6 +143,9 @@ func ExportExcalidraw(g *graph.Graph, outputPath string) error {
 		case "interface":
 			bgColor = "#fff0f6" // Light Pink
 			strokeColor = "#eb2f96"
+		case "external":
+			bgColor = "#f5f5f5" // Light Grey
+			strokeColor = "#8c8c8c"
 		}
 
 		currentX := 0.0
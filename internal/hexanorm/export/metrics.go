@@ -0,0 +1,59 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"strconv"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/analysis"
+)
+
+// ExportMetrics writes the package metrics as JSON.
+func ExportMetrics(m analysis.Metrics, outputPath string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, append(data, '\n'), 0o644)
+}
+
+// ExportMetricsCSV writes the package metrics as CSV, one row per group, with its level
+// (directory, context or layer) in the first column.
+func ExportMetricsCSV(m analysis.Metrics, outputPath string) error {
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"level", "name", "files", "afferent_coupling", "efferent_coupling", "instability",
+		"abstract_types", "concrete_types", "abstractness", "distance"})
+	levels := []struct {
+		name   string
+		groups []analysis.PackageMetrics
+	}{
+		{"directory", m.Directories},
+		{"context", m.Contexts},
+		{"layer", m.Layers},
+	}
+	for _, level := range levels {
+		for _, g := range level.groups {
+			w.Write([]string{
+				level.name,
+				g.Name,
+				strconv.Itoa(g.Files),
+				strconv.Itoa(g.AfferentCoupling),
+				strconv.Itoa(g.EfferentCoupling),
+				strconv.FormatFloat(g.Instability, 'f', 2, 64),
+				strconv.Itoa(g.AbstractTypes),
+				strconv.Itoa(g.ConcreteTypes),
+				strconv.FormatFloat(g.Abstractness, 'f', 2, 64),
+				strconv.FormatFloat(g.DistanceFromMain, 'f', 2, 64),
+			})
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
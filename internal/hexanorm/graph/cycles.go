@@ -0,0 +1,164 @@
+package graph
+
+import (
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+)
+
+// Adjacency returns the successors of every node along edges of the given type.
+// Edges pointing to IDs that are not nodes of the graph are included.
+func (g *Graph) Adjacency(edgeType domain.EdgeType) map[string][]string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	adj := make(map[string][]string)
+	for sourceID, edges := range g.edges {
+		for _, e := range edges {
+			if e.Type == edgeType {
+				adj[sourceID] = append(adj[sourceID], e.TargetID)
+			}
+		}
+	}
+	return adj
+}
+
+// StronglyConnectedComponents returns the cyclic strongly connected components formed by edges of the given type.
+// Only components that contain a cycle are returned: those with several nodes, or a single node with a self-loop.
+func (g *Graph) StronglyConnectedComponents(edgeType domain.EdgeType) [][]string {
+	return StronglyConnectedComponents(g.Adjacency(edgeType))
+}
+
+// Cycles returns one cycle path per cyclic strongly connected component formed by edges of the given type.
+// Each path starts and ends with the same node, e.g. [a b c a].
+func (g *Graph) Cycles(edgeType domain.EdgeType) [][]string {
+	return Cycles(g.Adjacency(edgeType))
+}
+
+// StronglyConnectedComponents runs Tarjan's algorithm on an adjacency map and returns its cyclic components.
+// Nodes inside each component, and the components themselves, are sorted for deterministic output.
+func StronglyConnectedComponents(adj map[string][]string) [][]string {
+	ids := make([]string, 0, len(adj))
+	for id := range adj {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	index := 0
+	indices := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+
+	var strongConnect func(v string)
+	strongConnect = func(v string) {
+		indices[v] = index
+		lowlink[v] = index
+		index++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range adj[v] {
+			if _, visited := indices[w]; !visited {
+				strongConnect(w)
+				lowlink[v] = min(lowlink[v], lowlink[w])
+			} else if onStack[w] {
+				lowlink[v] = min(lowlink[v], indices[w])
+			}
+		}
+
+		if lowlink[v] == indices[v] {
+			var component []string
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+			if len(component) > 1 || hasSelfLoop(adj, v) {
+				sort.Strings(component)
+				components = append(components, component)
+			}
+		}
+	}
+
+	for _, id := range ids {
+		if _, visited := indices[id]; !visited {
+			strongConnect(id)
+		}
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		return components[i][0] < components[j][0]
+	})
+	return components
+}
+
+// Cycles returns one shortest cycle path through the first node of every cyclic strongly connected component.
+func Cycles(adj map[string][]string) [][]string {
+	var cycles [][]string
+	for _, component := range StronglyConnectedComponents(adj) {
+		if path := ShortestCycle(adj, component); path != nil {
+			cycles = append(cycles, path)
+		}
+	}
+	return cycles
+}
+
+// ShortestCycle returns the shortest cycle path through the first node of a strongly connected component,
+// staying inside the component. A component may hold more nodes than its shortest cycle goes through.
+func ShortestCycle(adj map[string][]string, component []string) []string {
+	members := make(map[string]bool, len(component))
+	for _, id := range component {
+		members[id] = true
+	}
+	return shortestCycle(adj, component[0], members)
+}
+
+// shortestCycle finds the shortest path from start back to itself, staying inside the given members.
+func shortestCycle(adj map[string][]string, start string, members map[string]bool) []string {
+	parent := map[string]string{}
+	queue := []string{start}
+	visited := map[string]bool{}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, next := range adj[current] {
+			if next == start {
+				// Rebuild the path start -> ... -> current -> start
+				path := []string{start}
+				for n := current; n != start; n = parent[n] {
+					path = append(path, n)
+				}
+				path = append(path, start)
+				// Reverse the inner part, which was collected backwards
+				for i, j := 1, len(path)-2; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return path
+			}
+			if members[next] && !visited[next] {
+				visited[next] = true
+				parent[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// hasSelfLoop reports whether the node has an edge to itself.
+func hasSelfLoop(adj map[string][]string, id string) bool {
+	for _, w := range adj[id] {
+		if w == id {
+			return true
+		}
+	}
+	return false
+}
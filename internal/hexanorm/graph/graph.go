@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
@@ -93,6 +94,73 @@ func (g *Graph) RemoveNode(id string) {
 	}
 }
 
+// RemoveEdge removes the edge of the given type between two nodes.
+// It also removes the edge from the persistent store.
+func (g *Graph) RemoveEdge(sourceID, targetID string, edgeType domain.EdgeType) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.edges[sourceID] = removeEdge(g.edges[sourceID], sourceID, targetID, edgeType)
+	if len(g.edges[sourceID]) == 0 {
+		delete(g.edges, sourceID)
+	}
+	g.reverseEdges[targetID] = removeEdge(g.reverseEdges[targetID], sourceID, targetID, edgeType)
+	if len(g.reverseEdges[targetID]) == 0 {
+		delete(g.reverseEdges, targetID)
+	}
+
+	if g.store != nil {
+		g.store.DeleteEdge(&domain.Edge{SourceID: sourceID, TargetID: targetID, Type: edgeType})
+	}
+}
+
+// removeEdge returns the edges without the given one.
+func removeEdge(edges []*domain.Edge, sourceID, targetID string, edgeType domain.EdgeType) []*domain.Edge {
+	kept := edges[:0]
+	for _, e := range edges {
+		if e.SourceID != sourceID || e.TargetID != targetID || e.Type != edgeType {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// RemoveEdgesFrom removes all edges of the given type leaving a node.
+// It also removes the edges from the persistent store.
+func (g *Graph) RemoveEdgesFrom(sourceID string, edgeType domain.EdgeType) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var kept []*domain.Edge
+	for _, edge := range g.edges[sourceID] {
+		if edge.Type != edgeType {
+			kept = append(kept, edge)
+			continue
+		}
+		incoming := g.reverseEdges[edge.TargetID]
+		for i, e := range incoming {
+			if e == edge {
+				incoming = append(incoming[:i], incoming[i+1:]...)
+				break
+			}
+		}
+		if len(incoming) == 0 {
+			delete(g.reverseEdges, edge.TargetID)
+		} else {
+			g.reverseEdges[edge.TargetID] = incoming
+		}
+	}
+	if len(kept) == 0 {
+		delete(g.edges, sourceID)
+	} else {
+		g.edges[sourceID] = kept
+	}
+
+	if g.store != nil {
+		g.store.DeleteEdgesFrom(sourceID, edgeType)
+	}
+}
+
 // removeForwardEdge removes a specific edge from the forward edges map.
 func (g *Graph) removeForwardEdge(sourceID, targetID string) {
 	edges := g.edges[sourceID]
@@ -201,7 +269,8 @@ func (g *Graph) GetEdgesTo(targetID string) []*domain.Edge {
 }
 
 // BlastRadius calculates the potential impact of changing a specific code node.
-// It performs a reverse traversal to find all features and requirements that depend on the given code ID.
+// It performs a reverse traversal to find all features and requirements that depend on the given code ID,
+// including the requirements verified by the scenarios executing it.
 // It returns a list of impacted feature IDs and a list of impacted requirement IDs.
 func (g *Graph) BlastRadius(codeID string) ([]string, []string) {
 	g.mu.RLock()
@@ -219,6 +288,15 @@ func (g *Graph) BlastRadius(codeID string) ([]string, []string) {
 		currentID := queue[0]
 		queue = queue[1:]
 
+		// Scenarios and tests reaching the code verify requirements
+		for _, edge := range g.edges[currentID] {
+			if edge.Type == domain.EdgeTypeVerifies {
+				if target, exists := g.nodes[edge.TargetID]; exists && target.Kind == domain.NodeKindRequirement {
+					impactedRequirements[target.ID] = true
+				}
+			}
+		}
+
 		for _, edge := range g.reverseEdges[currentID] {
 			if !visited[edge.SourceID] {
 				sourceNode, exists := g.nodes[edge.SourceID]
@@ -228,7 +306,9 @@ func (g *Graph) BlastRadius(codeID string) ([]string, []string) {
 
 				if edge.Type == domain.EdgeTypeImplementedBy ||
 					edge.Type == domain.EdgeTypeDefines ||
-					edge.Type == domain.EdgeTypeCalls {
+					edge.Type == domain.EdgeTypeCalls ||
+					edge.Type == domain.EdgeTypeContains ||
+					edge.Type == domain.EdgeTypeExecutes {
 
 					visited[edge.SourceID] = true
 					queue = append(queue, edge.SourceID)
@@ -256,6 +336,54 @@ func (g *Graph) BlastRadius(codeID string) ([]string, []string) {
 	return features, requirements
 }
 
+// CoveringTests returns the IDs of the tests covering a code node: the tests verifying the node,
+// the symbols it contains, the Go package containing it, or the code calling them, directly or transitively.
+func (g *Graph) CoveringTests(codeID string) []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	visited := map[string]bool{codeID: true}
+	queue := []string{codeID}
+
+	// A file or type is covered through the symbols it contains
+	for i := 0; i < len(queue); i++ {
+		for _, edge := range g.edges[queue[i]] {
+			if edge.Type == domain.EdgeTypeContains && !visited[edge.TargetID] {
+				visited[edge.TargetID] = true
+				queue = append(queue, edge.TargetID)
+			}
+		}
+	}
+
+	tests := make(map[string]bool)
+	for len(queue) > 0 {
+		currentID := queue[0]
+		queue = queue[1:]
+
+		for _, edge := range g.reverseEdges[currentID] {
+			sourceNode, exists := g.nodes[edge.SourceID]
+			if !exists || visited[edge.SourceID] {
+				continue
+			}
+			switch {
+			case edge.Type == domain.EdgeTypeVerifies && sourceNode.Kind == domain.NodeKindTest:
+				tests[sourceNode.ID] = true
+			case edge.Type == domain.EdgeTypeCalls && sourceNode.Kind == domain.NodeKindSymbol,
+				edge.Type == domain.EdgeTypeContains && sourceNode.Kind == domain.NodeKindPackage:
+				visited[edge.SourceID] = true
+				queue = append(queue, edge.SourceID)
+			}
+		}
+	}
+
+	res := make([]string, 0, len(tests))
+	for id := range tests {
+		res = append(res, id)
+	}
+	sort.Strings(res)
+	return res
+}
+
 // Clear removes all nodes and edges from the in-memory graph.
 // Warning: This does not affect the persistent store.
 func (g *Graph) Clear() {
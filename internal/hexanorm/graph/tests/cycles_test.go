@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/graph"
+)
+
+func TestCycles(t *testing.T) {
+	g := graph.NewGraph(nil)
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		g.AddNode(&domain.Node{ID: id, Kind: domain.NodeKindCode})
+	}
+	// a -> b -> c -> a is a cycle, d -> d is a self-loop, e is acyclic.
+	g.AddEdge("a", "b", domain.EdgeTypeImports)
+	g.AddEdge("b", "c", domain.EdgeTypeImports)
+	g.AddEdge("c", "a", domain.EdgeTypeImports)
+	g.AddEdge("c", "e", domain.EdgeTypeImports)
+	g.AddEdge("d", "d", domain.EdgeTypeImports)
+	g.AddEdge("e", "a", domain.EdgeTypeCalls) // Other edge types are ignored.
+
+	components := g.StronglyConnectedComponents(domain.EdgeTypeImports)
+	want := [][]string{{"a", "b", "c"}, {"d"}}
+	if !reflect.DeepEqual(components, want) {
+		t.Errorf("StronglyConnectedComponents() = %v, want %v", components, want)
+	}
+
+	cycles := g.Cycles(domain.EdgeTypeImports)
+	wantCycles := [][]string{{"a", "b", "c", "a"}, {"d", "d"}}
+	if !reflect.DeepEqual(cycles, wantCycles) {
+		t.Errorf("Cycles() = %v, want %v", cycles, wantCycles)
+	}
+
+	// A shortcut a -> c keeps the component whole but shortens its cycle
+	g.AddEdge("a", "c", domain.EdgeTypeImports)
+	adj := g.Adjacency(domain.EdgeTypeImports)
+	if got, want := graph.ShortestCycle(adj, []string{"a", "b", "c"}), []string{"a", "c", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ShortestCycle() = %v, want %v", got, want)
+	}
+}
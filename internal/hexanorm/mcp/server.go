@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -45,12 +46,17 @@ func NewServer(rootDir string) (*mcp.Server, error) {
 	}
 
 	g := graph.NewGraph(st)
-	an := analysis.NewAnalyzer(g)
+	an := analysis.NewAnalyzer(g, cfg)
+	an.RootDir = rootDir
+	if an.Baseline, err = analysis.LoadBaseline(filepath.Join(rootDir, cfg.BaselineFile)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load baseline: %v\n", err)
+	}
 
 	// Scan initial root
 	scanDirectory(rootDir, an)
-	// Index steps
+	// Index steps and calls
 	an.IndexStepDefinitions()
+	an.IndexCallGraph()
 
 	w, err := watcher.NewWatcher(rootDir, an, g, cfg)
 	if err != nil {
@@ -81,12 +87,12 @@ func NewServer(rootDir string) (*mcp.Server, error) {
 
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "link_requirement",
-		Description: "Links a file to a requirement",
+		Description: "Links a file or symbol (e.g. path#Type.method) to a requirement",
 	}, hs.linkRequirement)
 
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "blast_radius",
-		Description: "Analyze impact of changing a code node",
+		Description: "Analyze impact of changing a code node or symbol (e.g. path#Type.method)",
 	}, hs.blastRadius)
 
 	mcp.AddTool(s, &mcp.Tool{
@@ -105,6 +111,11 @@ func NewServer(rootDir string) (*mcp.Server, error) {
 		URI:  "mcp://hexanorm/violations",
 	}, hs.handleViolations)
 
+	s.AddResource(&mcp.Resource{
+		Name: "metrics",
+		URI:  "mcp://hexanorm/metrics",
+	}, hs.handleMetrics)
+
 	s.AddResource(&mcp.Resource{
 		Name: "live_docs",
 		URI:  "mcp://hexanorm/live_docs",
@@ -119,6 +130,7 @@ func NewServer(rootDir string) (*mcp.Server, error) {
 }
 
 func scanDirectory(root string, an *analysis.Analyzer) {
+	var manifests, files []string
 	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -129,13 +141,24 @@ func scanDirectory(root string, an *analysis.Analyzer) {
 			}
 			return nil
 		}
-
-		content, err := os.ReadFile(path)
-		if err == nil {
-			an.AnalyzeFile(path, content)
+		if analysis.IsManifest(path) {
+			manifests = append(manifests, path)
+		} else {
+			files = append(files, path)
 		}
 		return nil
 	})
+
+	// Manifests first, so that imports resolve regardless of the walk order
+	for _, path := range append(manifests, files...) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if err := an.AnalyzeFile(path, content); err != nil {
+			log.Printf("Failed to analyze file %s: %v", path, err)
+		}
+	}
 }
 
 // Tool Inputs
@@ -217,6 +240,7 @@ func (hs *HexanormServer) blastRadius(ctx context.Context, req *mcp.CallToolRequ
 		"code_id":               input.CodeID,
 		"impacted_features":     features,
 		"impacted_requirements": reqs,
+		"covering_tests":        hs.Graph.CoveringTests(input.CodeID),
 	}
 
 	jsonBytes, _ := json.MarshalIndent(res, "", "  ")
@@ -255,8 +279,25 @@ func (hs *HexanormServer) handleStatus(ctx context.Context, req *mcp.ReadResourc
 }
 
 func (hs *HexanormServer) handleViolations(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-	violations := hs.Analyzer.FindViolations()
-	bytes, _ := json.MarshalIndent(violations, "", "  ")
+	// New violations first, those accepted by the baseline apart
+	res := map[string][]domain.Violation{"new": {}, "baselined": {}}
+	for _, v := range hs.Analyzer.FindViolations() {
+		if v.Baselined {
+			res["baselined"] = append(res["baselined"], v)
+		} else {
+			res["new"] = append(res["new"], v)
+		}
+	}
+	bytes, _ := json.MarshalIndent(res, "", "  ")
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{URI: req.Params.URI, MIMEType: "application/json", Text: string(bytes)},
+		},
+	}, nil
+}
+
+func (hs *HexanormServer) handleMetrics(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	bytes, _ := json.MarshalIndent(hs.Analyzer.CalculateMetrics(), "", "  ")
 	return &mcp.ReadResourceResult{
 		Contents: []*mcp.ResourceContents{
 			{URI: req.Params.URI, MIMEType: "application/json", Text: string(bytes)},
@@ -292,17 +333,30 @@ func (hs *HexanormServer) handleTraceability(ctx context.Context, req *mcp.ReadR
 			}
 			// Find implemented by
 			edges := hs.Graph.GetEdgesFrom(n.ID)
-			var code []string
+			var code, features []string
 			for _, e := range edges {
 				if e.Type == domain.EdgeTypeImplementedBy {
 					code = append(code, e.TargetID)
 				}
+				if e.Type == domain.EdgeTypeDescribedBy {
+					features = append(features, e.TargetID)
+				}
 			}
 			entry["code"] = code
+			entry["gherkin_features"] = features
+
+			// Find the unit and integration tests covering the code
+			var tests []string
+			for _, c := range code {
+				for _, t := range hs.Graph.CoveringTests(c) {
+					tests = appendUnique(tests, t)
+				}
+			}
+			entry["tests"] = tests
 
 			// Find verifiers (Tests) - Reverse edge VERIFIES
 			revEdges := hs.Graph.GetEdgesTo(n.ID)
-			var verifiers []string
+			var verifiers, stepDefs, exercised []string
 			for _, e := range revEdges {
 				if e.Type == domain.EdgeTypeVerifies {
 					verifiers = append(verifiers, e.SourceID)
@@ -310,6 +364,24 @@ func (hs *HexanormServer) handleTraceability(ctx context.Context, req *mcp.ReadR
 			}
 			entry["verifiers"] = verifiers
 
+			// Follow the thread: Scenario -> StepDefinition -> Code
+			for _, scID := range verifiers {
+				for _, e := range hs.Graph.GetEdgesFrom(scID) {
+					if e.Type == domain.EdgeTypeExecutes {
+						stepDefs = appendUnique(stepDefs, e.TargetID)
+					}
+				}
+			}
+			for _, sdID := range stepDefs {
+				for _, e := range hs.Graph.GetEdgesFrom(sdID) {
+					if e.Type == domain.EdgeTypeCalls {
+						exercised = appendUnique(exercised, e.TargetID)
+					}
+				}
+			}
+			entry["step_definitions"] = stepDefs
+			entry["exercised_code"] = exercised
+
 			matrix = append(matrix, entry)
 		}
 	}
@@ -321,3 +393,13 @@ func (hs *HexanormServer) handleTraceability(ctx context.Context, req *mcp.ReadR
 		},
 	}, nil
 }
+
+// appendUnique appends s to list unless it is already present.
+func appendUnique(list []string, s string) []string {
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
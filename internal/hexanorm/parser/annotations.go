@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Annotation represents a `@hexanorm:<directive>` annotation written in a source comment, e.g.
+// `// @hexanorm:implements REQ-42` or `// @hexanorm:ignore ARCH_LAYER_VIOLATION reason="legacy"`.
+type Annotation struct {
+	Directive  string            // The directive, e.g. `implements`, `layer` or `ignore`.
+	Args       []string          // The positional arguments, separated by spaces or commas.
+	Params     map[string]string // The `key="value"` arguments.
+	Line       int               // The line number of the annotation.
+	TargetLine int               // The line number of the declaration right below the comment, or 0 if it is followed by a blank line.
+}
+
+var (
+	annotationDirective = regexp.MustCompile(`@hexanorm:([\w-]+)([^\n]*)`)
+	annotationArg       = regexp.MustCompile(`([\w-]+)="([^"]*)"|([\w-]+)=([^\s,]+)|([^\s,]+)`)
+)
+
+// ParseAnnotations extracts the `@hexanorm:` annotations from the comments of the source code content.
+// Each comment may hold several annotations, one per line.
+func ParseAnnotations(content []byte, lang Language) ([]Annotation, error) {
+	sl := getLanguage(lang)
+	if sl == nil {
+		return nil, nil
+	}
+
+	parser := sitter.NewParser()
+	parser.SetLanguage(sl)
+	tree, err := parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return nil, err
+	}
+
+	var annotations []Annotation
+	var walk func(n *sitter.Node)
+	walk = func(n *sitter.Node) {
+		if isComment(n) {
+			annotations = append(annotations, parseComment(n, content)...)
+			return
+		}
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			walk(n.NamedChild(i))
+		}
+	}
+	walk(tree.RootNode())
+	return annotations, nil
+}
+
+// isComment reports whether the node is a comment: `comment`, `line_comment`, `block_comment` or `multiline_comment`.
+func isComment(n *sitter.Node) bool {
+	return strings.HasSuffix(n.Type(), "comment")
+}
+
+// declarationLine returns the line where a declaration starts, past the decorators of a Python decorated definition,
+// so that it matches the start line of the symbol.
+func declarationLine(n *sitter.Node) int {
+	if n.Type() == "decorated_definition" {
+		if def := n.ChildByFieldName("definition"); def != nil {
+			return line(def)
+		}
+	}
+	return line(n)
+}
+
+// parseComment returns the annotations of a comment node.
+func parseComment(n *sitter.Node, content []byte) []Annotation {
+	text := string(content[n.StartByte():n.EndByte()])
+	if !strings.Contains(text, "@hexanorm:") {
+		return nil
+	}
+
+	// The declaration documented by the comment: right below it, past the other comments above it
+	target := 0
+	last, next := n, n.NextNamedSibling()
+	for next != nil && isComment(next) && line(next) == endLine(last)+1 {
+		last, next = next, next.NextNamedSibling()
+	}
+	if next != nil && !isComment(next) && line(next) == endLine(last)+1 {
+		target = declarationLine(next)
+	}
+
+	var annotations []Annotation
+	for _, m := range annotationDirective.FindAllStringSubmatchIndex(text, -1) {
+		a := Annotation{
+			Directive:  text[m[2]:m[3]],
+			Params:     make(map[string]string),
+			Line:       line(n) + strings.Count(text[:m[0]], "\n"),
+			TargetLine: target,
+		}
+		rest := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text[m[4]:m[5]]), "*/"))
+		for _, arg := range annotationArg.FindAllStringSubmatch(rest, -1) {
+			switch {
+			case arg[1] != "":
+				a.Params[arg[1]] = arg[2]
+			case arg[3] != "":
+				a.Params[arg[3]] = arg[4]
+			default:
+				a.Args = append(a.Args, arg[5])
+			}
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations
+}
@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"context"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// UnknownReceiver is the receiver of calls made on expressions that are not simple names,
+// such as the result of another call.
+const UnknownReceiver = "?"
+
+// Call represents a function or method call found in source code.
+type Call struct {
+	Name     string // The name of the called function or method.
+	Receiver string // The last name of the receiver (`this`, a variable, a type or a module), empty for plain calls.
+	Line     int    // The line number of the call.
+}
+
+// Callee returns the call in `receiver.name` form, or the name alone for plain calls.
+func (c Call) Callee() string {
+	if c.Receiver != "" {
+		return c.Receiver + "." + c.Name
+	}
+	return c.Name
+}
+
+// ParseCalls extracts the function and method calls made in the source code.
+func ParseCalls(content []byte, lang Language) ([]Call, error) {
+	sl := getLanguage(lang)
+	if sl == nil {
+		return nil, nil
+	}
+
+	parser := sitter.NewParser()
+	parser.SetLanguage(sl)
+	tree, err := parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &callCollector{content: content}
+	c.walk(tree.RootNode())
+	return c.calls, nil
+}
+
+// callCollector accumulates calls while walking a syntax tree.
+type callCollector struct {
+	content []byte
+	calls   []Call
+}
+
+func (c *callCollector) walk(n *sitter.Node) {
+	switch n.Type() {
+	case "call_expression", "call":
+		// Go, TypeScript and Rust call_expression, Python call
+		c.addCallee(n, n.ChildByFieldName("function"))
+	case "function_call_expression":
+		// PHP plain calls, possibly namespaced: \App\helper()
+		c.add(n, c.lastName(n.ChildByFieldName("function")), "")
+	case "member_call_expression", "nullsafe_member_call_expression":
+		c.add(n, c.text(n.ChildByFieldName("name")), c.receiver(n.ChildByFieldName("object")))
+	case "scoped_call_expression":
+		c.add(n, c.text(n.ChildByFieldName("name")), c.receiver(n.ChildByFieldName("scope")))
+	}
+
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		c.walk(n.NamedChild(i))
+	}
+}
+
+// addCallee records a call whose callee is an expression: a name, a member access or a path.
+func (c *callCollector) addCallee(call, fn *sitter.Node) {
+	if fn == nil {
+		return
+	}
+	switch fn.Type() {
+	case "identifier":
+		c.add(call, c.text(fn), "")
+	case "selector_expression":
+		// Go: recv.Method()
+		c.add(call, c.text(fn.ChildByFieldName("field")), c.receiver(fn.ChildByFieldName("operand")))
+	case "member_expression":
+		// TypeScript: recv.method()
+		c.add(call, c.text(fn.ChildByFieldName("property")), c.receiver(fn.ChildByFieldName("object")))
+	case "attribute":
+		// Python: recv.method()
+		c.add(call, c.text(fn.ChildByFieldName("attribute")), c.receiver(fn.ChildByFieldName("object")))
+	case "field_expression":
+		// Rust: recv.method()
+		c.add(call, c.text(fn.ChildByFieldName("field")), c.receiver(fn.ChildByFieldName("value")))
+	case "scoped_identifier":
+		// Rust: Type::function() or module::function()
+		c.add(call, c.text(fn.ChildByFieldName("name")), c.receiver(fn.ChildByFieldName("path")))
+	case "generic_function":
+		// Rust: function::<T>()
+		c.addCallee(call, fn.ChildByFieldName("function"))
+	}
+}
+
+func (c *callCollector) add(n *sitter.Node, name, receiver string) {
+	if name == "" {
+		return
+	}
+	c.calls = append(c.calls, Call{Name: name, Receiver: receiver, Line: line(n)})
+}
+
+// receiver returns the last name of a receiver expression, or UnknownReceiver if it is not a simple name or path.
+func (c *callCollector) receiver(n *sitter.Node) string {
+	if n == nil {
+		return UnknownReceiver
+	}
+	switch n.Type() {
+	case "identifier", "type_identifier", "this", "self", "super", "crate", "name", "variable_name", "relative_scope":
+		return c.text(n)
+	case "selector_expression":
+		return c.text(n.ChildByFieldName("field"))
+	case "member_expression":
+		return c.text(n.ChildByFieldName("property"))
+	case "attribute":
+		return c.text(n.ChildByFieldName("attribute"))
+	case "field_expression":
+		return c.text(n.ChildByFieldName("field"))
+	case "scoped_identifier", "member_access_expression", "nullsafe_member_access_expression":
+		return c.text(n.ChildByFieldName("name"))
+	case "qualified_name":
+		return c.lastName(n)
+	}
+	return UnknownReceiver
+}
+
+// lastName returns the last segment of a possibly qualified PHP name.
+func (c *callCollector) lastName(n *sitter.Node) string {
+	text := c.text(n)
+	if i := strings.LastIndex(text, `\`); i >= 0 {
+		return text[i+1:]
+	}
+	return text
+}
+
+func (c *callCollector) text(n *sitter.Node) string {
+	if n == nil {
+		return ""
+	}
+	return string(c.content[n.StartByte():n.EndByte()])
+}
@@ -0,0 +1,483 @@
+package parser
+
+import (
+	"context"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// TypeKind represents the kind of a declared type.
+type TypeKind string
+
+// Constants for the kinds of declared types.
+const (
+	TypeKindInterface     TypeKind = "interface"      // Interfaces, Python ABCs and Protocols.
+	TypeKindTrait         TypeKind = "trait"          // Rust traits.
+	TypeKindAbstractClass TypeKind = "abstract_class" // Classes that cannot be instantiated.
+	TypeKindClass         TypeKind = "class"          // Concrete classes.
+	TypeKindStruct        TypeKind = "struct"         // Go and Rust structs.
+)
+
+// TypeDecl represents a type declared in source code, such as an interface or a class.
+type TypeDecl struct {
+	Name       string   // The name of the type.
+	Kind       TypeKind // The kind of the type.
+	Implements []string // Simple names of the interfaces and base types it explicitly implements or extends.
+	Methods    []string // Names of the methods it declares.
+	Line       int      // The line number where the type is declared.
+	EndLine    int      // The line number where the declaration ends.
+}
+
+// SymbolKind represents the kind of a symbol declared in source code.
+type SymbolKind string
+
+// Constants for the kinds of symbols. Type symbols share their values with TypeKind.
+const (
+	SymbolKindInterface     SymbolKind = SymbolKind(TypeKindInterface)
+	SymbolKindTrait         SymbolKind = SymbolKind(TypeKindTrait)
+	SymbolKindAbstractClass SymbolKind = SymbolKind(TypeKindAbstractClass)
+	SymbolKindClass         SymbolKind = SymbolKind(TypeKindClass)
+	SymbolKindStruct        SymbolKind = SymbolKind(TypeKindStruct)
+	SymbolKindFunction      SymbolKind = "function"
+	SymbolKindMethod        SymbolKind = "method"
+)
+
+// Symbol represents a declaration in source code: a type, a function or a method.
+type Symbol struct {
+	Name      string     // The name of the symbol.
+	Kind      SymbolKind // The kind of the symbol.
+	Parent    string     // The name of the type declaring the method, for methods.
+	StartLine int        // The line number where the declaration starts.
+	EndLine   int        // The line number where the declaration ends.
+}
+
+// QualifiedName returns the name of the symbol prefixed by its parent type, e.g. `VatService.calculate`.
+func (s Symbol) QualifiedName() string {
+	if s.Parent != "" {
+		return s.Parent + "." + s.Name
+	}
+	return s.Name
+}
+
+// IsAbstract reports whether the type only describes a contract, i.e. it is an interface, trait or abstract class.
+func (t TypeDecl) IsAbstract() bool {
+	return t.Kind == TypeKindInterface || t.Kind == TypeKindTrait || t.Kind == TypeKindAbstractClass
+}
+
+// ParseTypeDeclarations extracts the interfaces, classes, structs and traits declared in the source code.
+// Methods declared outside the type body (Go receivers, Rust impl blocks) are attached to the type
+// when it is declared in the same file.
+func ParseTypeDeclarations(content []byte, lang Language) ([]TypeDecl, error) {
+	c, err := collectDeclarations(content, lang)
+	if c == nil {
+		return nil, err
+	}
+	return c.types, nil
+}
+
+// ParseSymbols extracts the types, functions and methods declared in the source code, with their line ranges.
+// Functions nested in other functions and methods without a body (interface members) are not symbols.
+func ParseSymbols(content []byte, lang Language) ([]Symbol, error) {
+	c, err := collectDeclarations(content, lang)
+	if c == nil {
+		return nil, err
+	}
+	return c.symbols, nil
+}
+
+// collectDeclarations parses the source code and walks its syntax tree to collect declarations.
+func collectDeclarations(content []byte, lang Language) (*declCollector, error) {
+	sl := getLanguage(lang)
+	if sl == nil {
+		return nil, nil
+	}
+
+	parser := sitter.NewParser()
+	parser.SetLanguage(sl)
+	tree, err := parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &declCollector{content: content, lang: lang, index: make(map[string]int)}
+	c.walk(tree.RootNode())
+	c.attachDetachedMethods()
+	return c, nil
+}
+
+// declCollector accumulates type declarations and symbols while walking a syntax tree.
+type declCollector struct {
+	content []byte
+	lang    Language
+	types   []TypeDecl
+	symbols []Symbol
+	index   map[string]int // Type name -> position in types
+	// Methods declared outside their type body, keyed by type name
+	detached      map[string][]string
+	detachedImpls map[string][]string
+}
+
+func (c *declCollector) text(n *sitter.Node) string {
+	if n == nil {
+		return ""
+	}
+	return n.Content(c.content)
+}
+
+// add records a type declaration, ending where the node does, along with its symbol.
+func (c *declCollector) add(n *sitter.Node, t TypeDecl) {
+	t.EndLine = endLine(n)
+	c.index[t.Name] = len(c.types)
+	c.types = append(c.types, t)
+	c.symbols = append(c.symbols, Symbol{Name: t.Name, Kind: SymbolKind(t.Kind), StartLine: line(n), EndLine: endLine(n)})
+}
+
+// addFunction records a function, or a method if it is declared inside a type.
+// Functions nested in other functions are ignored.
+func (c *declCollector) addFunction(n *sitter.Node, name, parent string) {
+	if name == "" {
+		return
+	}
+	owner, nested := c.owner(n)
+	if nested {
+		return
+	}
+	if parent == "" {
+		parent = owner
+	}
+	kind := SymbolKindFunction
+	if parent != "" {
+		kind = SymbolKindMethod
+	}
+	c.symbols = append(c.symbols, Symbol{Name: name, Kind: kind, Parent: parent, StartLine: line(n), EndLine: endLine(n)})
+}
+
+// owner returns the name of the type enclosing n, and whether n is nested inside a function body.
+func (c *declCollector) owner(n *sitter.Node) (string, bool) {
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		switch p.Type() {
+		case "function_declaration", "method_declaration", "func_literal", "method_definition",
+			"arrow_function", "function_expression", "function", "generator_function_declaration",
+			"function_definition", "function_item", "closure_expression", "anonymous_function_creation_expression":
+			return "", true
+		case "class_declaration", "abstract_class_declaration", "class", "class_definition",
+			"interface_declaration", "trait_item", "trait_declaration":
+			return c.text(p.ChildByFieldName("name")), false
+		case "impl_item":
+			return simpleTypeName(c.text(p.ChildByFieldName("type"))), false
+		}
+	}
+	return "", false
+}
+
+func (c *declCollector) walk(n *sitter.Node) {
+	switch c.lang {
+	case LangGo:
+		c.visitGo(n)
+	case LangTypeScript, LangTSX, LangJavaScript:
+		c.visitTS(n)
+	case LangPython:
+		c.visitPython(n)
+	case LangRust:
+		c.visitRust(n)
+	case LangPHP:
+		c.visitPHP(n)
+	}
+
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		c.walk(n.NamedChild(i))
+	}
+}
+
+func (c *declCollector) visitGo(n *sitter.Node) {
+	switch n.Type() {
+	case "type_spec":
+		name := c.text(n.ChildByFieldName("name"))
+		typ := n.ChildByFieldName("type")
+		if typ == nil {
+			return
+		}
+		switch typ.Type() {
+		case "interface_type":
+			c.add(n, TypeDecl{
+				Name:    name,
+				Kind:    TypeKindInterface,
+				Methods: c.childNames(typ, "method_elem", "method_spec"),
+				Line:    line(n),
+			})
+		case "struct_type":
+			c.add(n, TypeDecl{Name: name, Kind: TypeKindStruct, Line: line(n)})
+		}
+	case "function_declaration":
+		c.addFunction(n, c.text(n.ChildByFieldName("name")), "")
+	case "method_declaration":
+		receiver := n.ChildByFieldName("receiver")
+		name := c.text(n.ChildByFieldName("name"))
+		if typeName := c.firstOfType(receiver, "type_identifier"); typeName != "" {
+			c.addDetached(typeName, name, "")
+			c.addFunction(n, name, typeName)
+		}
+	}
+}
+
+func (c *declCollector) visitTS(n *sitter.Node) {
+	switch n.Type() {
+	case "interface_declaration":
+		c.add(n, TypeDecl{
+			Name:       c.text(n.ChildByFieldName("name")),
+			Kind:       TypeKindInterface,
+			Implements: c.heritage(n, "extends_type_clause"),
+			Methods:    c.childNames(n.ChildByFieldName("body"), "method_signature"),
+			Line:       line(n),
+		})
+	case "class_declaration", "abstract_class_declaration", "class":
+		name := c.text(n.ChildByFieldName("name"))
+		if name == "" {
+			return
+		}
+		kind := TypeKindClass
+		if n.Type() == "abstract_class_declaration" {
+			kind = TypeKindAbstractClass
+		}
+		var supers []string
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			if h := n.NamedChild(i); h.Type() == "class_heritage" {
+				supers = append(supers, c.heritage(h, "extends_clause", "implements_clause")...)
+			}
+		}
+		c.add(n, TypeDecl{
+			Name:       name,
+			Kind:       kind,
+			Implements: supers,
+			Methods:    c.childNames(n.ChildByFieldName("body"), "method_definition", "abstract_method_signature", "method_signature"),
+			Line:       line(n),
+		})
+	case "function_declaration", "generator_function_declaration", "method_definition":
+		c.addFunction(n, c.text(n.ChildByFieldName("name")), "")
+	case "variable_declarator", "public_field_definition":
+		// Arrow functions and function expressions bound to a name: `const f = () => {}`
+		switch value := n.ChildByFieldName("value"); {
+		case value == nil:
+		case value.Type() == "arrow_function", value.Type() == "function_expression", value.Type() == "function":
+			c.addFunction(n, c.text(n.ChildByFieldName("name")), "")
+		}
+	}
+}
+
+func (c *declCollector) visitPython(n *sitter.Node) {
+	if n.Type() == "function_definition" {
+		c.addFunction(n, c.text(n.ChildByFieldName("name")), "")
+		return
+	}
+	if n.Type() != "class_definition" {
+		return
+	}
+	kind := TypeKindClass
+	var supers []string
+	if args := n.ChildByFieldName("superclasses"); args != nil {
+		for i := 0; i < int(args.NamedChildCount()); i++ {
+			arg := args.NamedChild(i)
+			if arg.Type() == "keyword_argument" {
+				if simpleTypeName(c.text(arg.ChildByFieldName("value"))) == "ABCMeta" {
+					kind = TypeKindInterface
+				}
+				continue
+			}
+			switch base := simpleTypeName(c.text(arg)); base {
+			case "ABC", "Protocol":
+				kind = TypeKindInterface
+			case "object", "Generic":
+			default:
+				supers = append(supers, base)
+			}
+		}
+	}
+
+	var methods []string
+	if body := n.ChildByFieldName("body"); body != nil {
+		for i := 0; i < int(body.NamedChildCount()); i++ {
+			def := body.NamedChild(i)
+			if def.Type() == "decorated_definition" {
+				def = def.ChildByFieldName("definition")
+			}
+			if def != nil && def.Type() == "function_definition" {
+				methods = append(methods, c.text(def.ChildByFieldName("name")))
+			}
+		}
+	}
+
+	c.add(n, TypeDecl{
+		Name:       c.text(n.ChildByFieldName("name")),
+		Kind:       kind,
+		Implements: supers,
+		Methods:    methods,
+		Line:       line(n),
+	})
+}
+
+func (c *declCollector) visitRust(n *sitter.Node) {
+	switch n.Type() {
+	case "trait_item":
+		c.add(n, TypeDecl{
+			Name:    c.text(n.ChildByFieldName("name")),
+			Kind:    TypeKindTrait,
+			Methods: c.childNames(n.ChildByFieldName("body"), "function_signature_item", "function_item"),
+			Line:    line(n),
+		})
+	case "struct_item", "enum_item":
+		c.add(n, TypeDecl{Name: c.text(n.ChildByFieldName("name")), Kind: TypeKindStruct, Line: line(n)})
+	case "function_item":
+		c.addFunction(n, c.text(n.ChildByFieldName("name")), "")
+	case "impl_item":
+		typeName := simpleTypeName(c.text(n.ChildByFieldName("type")))
+		trait := simpleTypeName(c.text(n.ChildByFieldName("trait")))
+		methods := c.childNames(n.ChildByFieldName("body"), "function_item")
+		if len(methods) == 0 {
+			c.addDetached(typeName, "", trait)
+		}
+		for _, m := range methods {
+			c.addDetached(typeName, m, trait)
+		}
+	}
+}
+
+func (c *declCollector) visitPHP(n *sitter.Node) {
+	switch n.Type() {
+	case "interface_declaration":
+		c.add(n, TypeDecl{
+			Name:       c.text(n.ChildByFieldName("name")),
+			Kind:       TypeKindInterface,
+			Implements: c.heritage(n, "base_clause"),
+			Methods:    c.childNames(n.ChildByFieldName("body"), "method_declaration"),
+			Line:       line(n),
+		})
+	case "class_declaration":
+		kind := TypeKindClass
+		for i := 0; i < int(n.ChildCount()); i++ {
+			if n.Child(i).Type() == "abstract_modifier" {
+				kind = TypeKindAbstractClass
+			}
+		}
+		c.add(n, TypeDecl{
+			Name:       c.text(n.ChildByFieldName("name")),
+			Kind:       kind,
+			Implements: c.heritage(n, "base_clause", "class_interface_clause"),
+			Methods:    c.childNames(n.ChildByFieldName("body"), "method_declaration"),
+			Line:       line(n),
+		})
+	case "function_definition":
+		c.addFunction(n, c.text(n.ChildByFieldName("name")), "")
+	case "method_declaration":
+		// Methods without a body are interface or abstract members, not code.
+		if n.ChildByFieldName("body") != nil {
+			c.addFunction(n, c.text(n.ChildByFieldName("name")), "")
+		}
+	}
+}
+
+// heritage returns the simple type names listed in the given clause children of n.
+func (c *declCollector) heritage(n *sitter.Node, clauses ...string) []string {
+	var names []string
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		clause := n.NamedChild(i)
+		if !contains(clauses, clause.Type()) {
+			continue
+		}
+		for j := 0; j < int(clause.NamedChildCount()); j++ {
+			if name := simpleTypeName(c.text(clause.NamedChild(j))); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// childNames returns the `name` field of the direct children of n with one of the given types.
+func (c *declCollector) childNames(n *sitter.Node, types ...string) []string {
+	if n == nil {
+		return nil
+	}
+	var names []string
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		child := n.NamedChild(i)
+		if contains(types, child.Type()) {
+			if name := c.text(child.ChildByFieldName("name")); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// firstOfType returns the text of the first descendant of n with the given type.
+func (c *declCollector) firstOfType(n *sitter.Node, typ string) string {
+	if n == nil {
+		return ""
+	}
+	if n.Type() == typ {
+		return c.text(n)
+	}
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		if t := c.firstOfType(n.NamedChild(i), typ); t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+// addDetached records a method or implemented trait declared outside of the type body.
+func (c *declCollector) addDetached(typeName, method, implements string) {
+	if c.detached == nil {
+		c.detached = make(map[string][]string)
+		c.detachedImpls = make(map[string][]string)
+	}
+	if method != "" {
+		c.detached[typeName] = append(c.detached[typeName], method)
+	}
+	if implements != "" && !contains(c.detachedImpls[typeName], implements) {
+		c.detachedImpls[typeName] = append(c.detachedImpls[typeName], implements)
+	}
+}
+
+// attachDetachedMethods merges methods and traits declared outside of type bodies into their types.
+func (c *declCollector) attachDetachedMethods() {
+	for name, i := range c.index {
+		c.types[i].Methods = append(c.types[i].Methods, c.detached[name]...)
+		c.types[i].Implements = append(c.types[i].Implements, c.detachedImpls[name]...)
+	}
+}
+
+// simpleTypeName strips generic arguments, references and qualifiers from a type name,
+// e.g. `&mut repo::Store<T>` becomes `Store`.
+func simpleTypeName(s string) string {
+	s = strings.TrimLeft(strings.TrimSpace(s), "&*")
+	s = strings.TrimPrefix(s, "mut ")
+	if i := strings.IndexAny(s, "<[("); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.LastIndexAny(s, ".\\:"); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.TrimSpace(s)
+}
+
+// line returns the 1-based line number where the node starts.
+func line(n *sitter.Node) int {
+	return int(n.StartPoint().Row) + 1
+}
+
+// endLine returns the 1-based line number where the node ends.
+func endLine(n *sitter.Node) int {
+	return int(n.EndPoint().Row) + 1
+}
+
+// contains reports whether the slice contains the given string.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
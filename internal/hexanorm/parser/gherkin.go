@@ -1,85 +1,265 @@
 package parser
 
 import (
-	"bufio"
 	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"regexp"
 	"strings"
+
+	gherkin "github.com/cucumber/gherkin/go/v26"
+	messages "github.com/cucumber/messages/go/v21"
 )
 
+// gherkinLanguageHeader matches the `# language: xx` header, as the official parser does.
+var gherkinLanguageHeader = regexp.MustCompile(`^\s*#\s*language\s*:\s*([a-zA-Z\-_]+)\s*$`)
+
 // GherkinFeature represents a parsed .feature file containing one or more scenarios.
 type GherkinFeature struct {
 	Name      string            // The name of the feature.
-	Scenarios []GherkinScenario // List of scenarios defined in the feature.
+	Tags      []string          // The tags of the feature, e.g. `@billing`.
+	Language  string            // The language of the keywords, from the `# language:` header.
+	Line      int               // The line number where the feature starts.
+	Scenarios []GherkinScenario // List of scenarios defined in the feature, with outlines expanded.
+	Warnings  []string          // Problems that did not prevent parsing, e.g. an unknown language.
 }
 
 // GherkinScenario represents a single scenario within a feature file.
+// Scenario outlines are expanded into one scenario per example row.
 type GherkinScenario struct {
-	Name      string   // The name of the scenario.
-	Steps     []string // The raw text of the steps (Given/When/Then).
-	StepsHash string   // A hash of the steps used to detect changes or duplicates.
-	Line      int      // The line number where the scenario starts.
+	Name        string        // The name of the scenario, with outline parameters substituted.
+	Steps       []string      // The raw text of the steps (Given/When/Then), background steps first.
+	StepDetails []GherkinStep // The parsed steps, with their arguments.
+	StepsHash   string        // A hash of the steps used to detect changes or duplicates.
+	Line        int           // The line number where the scenario starts, or of its example row.
+	Tags        []string      // The tags of the scenario, including those inherited from its feature, rule and examples.
+	Rule        string        // The name of the rule the scenario belongs to, if any.
+	Example     int           // The 1-based index of the example row, for expanded outlines.
+}
+
+// GherkinStep represents a step with its arguments.
+type GherkinStep struct {
+	Keyword   string     `json:"keyword"`              // The keyword as written, without trailing space, e.g. `Given` or `Angenommen`.
+	Text      string     `json:"text"`                 // The text of the step, without the keyword.
+	Line      int        `json:"line"`                 // The line number of the step.
+	DataTable [][]string `json:"data_table,omitempty"` // The rows of the data table argument, if any.
+	DocString string     `json:"doc_string,omitempty"` // The content of the doc string argument, if any.
+}
+
+// String returns the step as written: keyword and text.
+func (s GherkinStep) String() string {
+	if strings.HasSuffix(s.Keyword, "'") {
+		return s.Keyword + s.Text
+	}
+	return s.Keyword + " " + s.Text
 }
 
-// ParseGherkin parses the content of a .feature file and returns a GherkinFeature struct.
-// It handles Feature and Scenario definitions and collects steps.
+// ParseGherkin parses the content of a .feature file with the official Gherkin parser and returns a GherkinFeature
+// struct, with its scenario outlines expanded and its background steps prepended to every scenario.
+// It supports tags, Background, Rule, Scenario Outline/Template with Examples, data tables, doc strings and
+// the keywords of every language of the official dialect table, declared with `# language:`.
+// A language missing from the table falls back to its base language (`fr` for `fr-XX`) or to English, with a warning.
 func ParseGherkin(content []byte) (*GherkinFeature, error) {
-	scanner := bufio.NewScanner(bytes.NewReader(content))
-	feature := &GherkinFeature{}
-	var currentScenario *GherkinScenario
-
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "@") {
+	content, warnings := fallbackLanguage(content)
+	doc, err := gherkin.ParseGherkinDocument(bytes.NewReader(content), (&messages.Incrementing{}).NewId)
+	if err != nil {
+		return nil, err
+	}
+
+	feat := &GherkinFeature{Language: gherkin.DefaultDialect, Warnings: warnings}
+	if doc.Feature == nil {
+		return feat, nil
+	}
+	feat.Name = doc.Feature.Name
+	feat.Language = doc.Feature.Language
+	feat.Line = int(doc.Feature.Location.Line)
+	feat.Tags = tagNames(doc.Feature.Tags)
+
+	var background []GherkinStep
+	for _, child := range doc.Feature.Children {
+		switch {
+		case child.Background != nil:
+			background = gherkinSteps(child.Background.Steps)
+		case child.Scenario != nil:
+			feat.addScenarios(child.Scenario, background, feat.Tags, "")
+		case child.Rule != nil:
+			ruleBackground := background
+			tags := mergeTags(feat.Tags, tagNames(child.Rule.Tags))
+			for _, ruleChild := range child.Rule.Children {
+				switch {
+				case ruleChild.Background != nil:
+					ruleBackground = append(append([]GherkinStep{}, background...), gherkinSteps(ruleChild.Background.Steps)...)
+				case ruleChild.Scenario != nil:
+					feat.addScenarios(ruleChild.Scenario, ruleBackground, tags, child.Rule.Name)
+				}
+			}
+		}
+	}
+	return feat, nil
+}
+
+// fallbackLanguage replaces a `# language:` header naming a language missing from the official dialect table
+// with its base language, or removes it to fall back to English, and returns a warning.
+func fallbackLanguage(content []byte) ([]byte, []string) {
+	lines := bytes.Split(content, []byte("\n"))
+	for i, line := range lines {
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) == 0 {
 			continue
 		}
+		m := gherkinLanguageHeader.FindSubmatch(trimmed)
+		if m == nil {
+			if bytes.HasPrefix(trimmed, []byte("#")) {
+				continue
+			}
+			// The header must precede the feature
+			return content, nil
+		}
+		lang := string(m[1])
+		if gherkin.DialectsBuiltin().GetDialect(lang) != nil {
+			return content, nil
+		}
+		fallback := gherkin.DefaultDialect
+		if base, _, ok := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-"); ok && gherkin.DialectsBuiltin().GetDialect(base) != nil {
+			fallback = base
+		}
+		lines[i] = []byte("# language: " + fallback)
+		warning := fmt.Sprintf("line %d: unsupported Gherkin language %q, parsed as %q", i+1, lang, fallback)
+		return bytes.Join(lines, []byte("\n")), []string{warning}
+	}
+	return content, nil
+}
+
+// addScenarios adds a scenario, or the expansion of an outline, to the feature.
+func (f *GherkinFeature) addScenarios(sc *messages.Scenario, background []GherkinStep, inherited []string, rule string) {
+	tags := mergeTags(inherited, tagNames(sc.Tags))
+	steps := gherkinSteps(sc.Steps)
+	if len(sc.Examples) == 0 {
+		f.addScenario(GherkinScenario{
+			Name:        sc.Name,
+			StepDetails: append(append([]GherkinStep{}, background...), steps...),
+			Line:        int(sc.Location.Line),
+			Tags:        tags,
+			Rule:        rule,
+		})
+		return
+	}
 
-		if strings.HasPrefix(line, "Feature:") {
-			feature.Name = strings.TrimSpace(strings.TrimPrefix(line, "Feature:"))
-		} else if strings.HasPrefix(line, "Scenario:") {
-			if currentScenario != nil {
-				finalizeScenario(currentScenario)
-				feature.Scenarios = append(feature.Scenarios, *currentScenario)
+	example := 0
+	for _, ex := range sc.Examples {
+		if ex.TableHeader == nil {
+			continue
+		}
+		for _, row := range ex.TableBody {
+			example++
+			values := make(map[string]string, len(ex.TableHeader.Cells))
+			for j, cell := range ex.TableHeader.Cells {
+				if j < len(row.Cells) {
+					values[cell.Value] = row.Cells[j].Value
+				}
 			}
-			currentScenario = &GherkinScenario{
-				Name: strings.TrimSpace(strings.TrimPrefix(line, "Scenario:")),
-				Line: lineNum,
+			expanded := append([]GherkinStep{}, background...)
+			for _, s := range steps {
+				expanded = append(expanded, substituteStep(s, values))
 			}
-		} else if isStep(line) {
-			if currentScenario != nil {
-				currentScenario.Steps = append(currentScenario.Steps, line)
+			f.addScenario(GherkinScenario{
+				Name:        substitute(sc.Name, values),
+				StepDetails: expanded,
+				Line:        int(row.Location.Line),
+				Tags:        mergeTags(tags, tagNames(ex.Tags)),
+				Rule:        rule,
+				Example:     example,
+			})
+		}
+	}
+}
+
+// gherkinSteps converts the steps of the Gherkin AST, with their arguments.
+func gherkinSteps(steps []*messages.Step) []GherkinStep {
+	res := make([]GherkinStep, 0, len(steps))
+	for _, s := range steps {
+		step := GherkinStep{
+			Keyword: strings.TrimSpace(s.Keyword),
+			Text:    s.Text,
+			Line:    int(s.Location.Line),
+		}
+		if s.DataTable != nil {
+			for _, row := range s.DataTable.Rows {
+				cells := make([]string, len(row.Cells))
+				for i, cell := range row.Cells {
+					cells[i] = cell.Value
+				}
+				step.DataTable = append(step.DataTable, cells)
 			}
 		}
+		if s.DocString != nil {
+			step.DocString = s.DocString.Content
+		}
+		res = append(res, step)
 	}
-	if currentScenario != nil {
-		finalizeScenario(currentScenario)
-		feature.Scenarios = append(feature.Scenarios, *currentScenario)
+	return res
+}
+
+// tagNames returns the names of the tags, e.g. `@billing`.
+func tagNames(tags []*messages.Tag) []string {
+	var names []string
+	for _, t := range tags {
+		names = append(names, t.Name)
 	}
+	return names
+}
 
-	return feature, nil
+// addScenario adds a scenario to the feature, with the hash of its steps.
+func (f *GherkinFeature) addScenario(sc GherkinScenario) {
+	h := sha256.New()
+	for _, s := range sc.StepDetails {
+		sc.Steps = append(sc.Steps, s.String())
+		h.Write([]byte(s.String()))
+		for _, row := range s.DataTable {
+			h.Write([]byte(strings.Join(row, "|")))
+		}
+		h.Write([]byte(s.DocString))
+	}
+	sc.StepsHash = hex.EncodeToString(h.Sum(nil))[:8]
+	f.Scenarios = append(f.Scenarios, sc)
 }
 
-func isStep(line string) bool {
-	words := strings.Fields(line)
-	if len(words) == 0 {
-		return false
+// substituteStep replaces the `<name>` outline parameters in a step and its arguments.
+func substituteStep(s GherkinStep, values map[string]string) GherkinStep {
+	s.Text = substitute(s.Text, values)
+	s.DocString = substitute(s.DocString, values)
+	if s.DataTable != nil {
+		table := make([][]string, len(s.DataTable))
+		for i, row := range s.DataTable {
+			table[i] = make([]string, len(row))
+			for j, cell := range row {
+				table[i][j] = substitute(cell, values)
+			}
+		}
+		s.DataTable = table
 	}
-	kw := words[0]
-	switch kw {
-	case "Given", "When", "Then", "And", "But":
-		return true
+	return s
+}
+
+func substitute(text string, values map[string]string) string {
+	for name, value := range values {
+		text = strings.ReplaceAll(text, "<"+name+">", value)
 	}
-	return false
+	return text
 }
 
-func finalizeScenario(sc *GherkinScenario) {
-	// Calculate hash of steps
-	h := sha256.New()
-	for _, s := range sc.Steps {
-		h.Write([]byte(s))
+// mergeTags concatenates tag lists, dropping duplicates.
+func mergeTags(lists ...[]string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, t := range list {
+			if !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
 	}
-	sc.StepsHash = hex.EncodeToString(h.Sum(nil))[:8]
+	return tags
 }
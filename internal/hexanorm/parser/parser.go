@@ -7,9 +7,13 @@ import (
 
 	sitter "github.com/smacker/go-tree-sitter"
 	"github.com/smacker/go-tree-sitter/golang"
+	"github.com/smacker/go-tree-sitter/java"
+	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/kotlin"
 	"github.com/smacker/go-tree-sitter/php"
 	"github.com/smacker/go-tree-sitter/python"
 	"github.com/smacker/go-tree-sitter/rust"
+	"github.com/smacker/go-tree-sitter/typescript/tsx"
 	"github.com/smacker/go-tree-sitter/typescript/typescript"
 )
 
@@ -19,26 +23,56 @@ type Language string
 // Constants for supported languages.
 const (
 	LangTypeScript Language = "typescript"
+	LangTSX        Language = "tsx"
+	LangJavaScript Language = "javascript"
 	LangGo         Language = "go"
 	LangPython     Language = "python"
 	LangRust       Language = "rust"
 	LangPHP        Language = "php"
+	LangJava       Language = "java"
+	LangKotlin     Language = "kotlin"
 	LangUnknown    Language = "unknown"
 )
 
 // StepDefFound represents a discovered BDD step definition in the code.
 type StepDefFound struct {
 	Pattern      string // The regex pattern or cucumber expression.
-	FunctionName string // The name of the function implementing the step.
-	Line         int    // The line number where the step definition starts.
+	FunctionName string // The name of the function implementing the step, empty for anonymous handlers.
+	Line         int    // The line number where the step handler starts.
+	EndLine      int    // The line number where the step handler ends.
+}
+
+// Import represents an import found in the source code, with the source range of the specifier
+// or statement declaring it. Columns are 1-based byte offsets, the end column is exclusive.
+type Import struct {
+	Path      string // The imported module, package, file or name, e.g. `./user`, `fmt` or `crate::domain::User`.
+	Line      int    // The line number where the import starts.
+	Column    int    // The column where the import starts.
+	EndLine   int    // The line number where the import ends.
+	EndColumn int    // The column after the end of the import.
+}
+
+// newImport returns the import of path declared by the node.
+func newImport(path string, n *sitter.Node) Import {
+	return Import{
+		Path:      path,
+		Line:      line(n),
+		Column:    int(n.StartPoint().Column) + 1,
+		EndLine:   endLine(n),
+		EndColumn: int(n.EndPoint().Column) + 1,
+	}
 }
 
 // DetectLanguage identifies the programming language based on the file extension.
 func DetectLanguage(filename string) Language {
 	ext := filepath.Ext(filename)
 	switch ext {
-	case ".ts", ".tsx":
+	case ".ts", ".mts", ".cts":
 		return LangTypeScript
+	case ".tsx":
+		return LangTSX
+	case ".js", ".jsx", ".mjs", ".cjs":
+		return LangJavaScript
 	case ".go":
 		return LangGo
 	case ".py":
@@ -47,6 +81,10 @@ func DetectLanguage(filename string) Language {
 		return LangRust
 	case ".php":
 		return LangPHP
+	case ".java":
+		return LangJava
+	case ".kt":
+		return LangKotlin
 	}
 	return LangUnknown
 }
@@ -55,6 +93,10 @@ func getLanguage(lang Language) *sitter.Language {
 	switch lang {
 	case LangTypeScript:
 		return typescript.GetLanguage()
+	case LangTSX:
+		return tsx.GetLanguage()
+	case LangJavaScript:
+		return javascript.GetLanguage()
 	case LangGo:
 		return golang.GetLanguage()
 	case LangPython:
@@ -63,14 +105,33 @@ func getLanguage(lang Language) *sitter.Language {
 		return rust.GetLanguage()
 	case LangPHP:
 		return php.GetLanguage()
+	case LangJava:
+		return java.GetLanguage()
+	case LangKotlin:
+		return kotlin.GetLanguage()
 	default:
 		return nil
 	}
 }
 
-// ParseImports extracts import statements from the source code content.
-// It uses tree-sitter queries specific to the detected language.
-func ParseImports(content []byte, lang Language) ([]string, error) {
+// jsImportQuery matches the module specifiers of JavaScript and TypeScript imports: `import`/`export ... from`
+// statements, dynamic `import()` and CommonJS `require()` calls with a constant specifier.
+const jsImportQuery = `
+(import_statement source: (string (string_fragment) @path))
+(export_statement source: (string (string_fragment) @path))
+(call_expression
+	function: (import)
+	arguments: (arguments . [(string (string_fragment) @path) (template_string . (string_fragment) @path .)]))
+(call_expression
+	function: (identifier) @require
+	arguments: (arguments . [(string (string_fragment) @path) (template_string . (string_fragment) @path .)])
+	(#eq? @require "require"))
+`
+
+// ParseImports extracts import statements from the source code content, with their positions.
+// It uses tree-sitter queries specific to the detected language. Imports expanded from a single statement,
+// such as Rust use trees or grouped PHP `use` declarations, share the range of the statement.
+func ParseImports(content []byte, lang Language) ([]Import, error) {
 	sl := getLanguage(lang)
 	if sl == nil {
 		return nil, nil
@@ -84,27 +145,42 @@ func ParseImports(content []byte, lang Language) ([]string, error) {
 
 	var queryStr string
 	switch lang {
-	case LangTypeScript:
-		queryStr = `
-		(import_statement source: (string (string_fragment) @path))
-		(export_statement source: (string (string_fragment) @path))
+	case LangTypeScript, LangTSX:
+		// TypeScript also has `import x = require('...')`
+		queryStr = jsImportQuery + `
+		(import_require_clause source: (string (string_fragment) @path))
 		`
+	case LangJavaScript:
+		queryStr = jsImportQuery
 	case LangGo:
 		queryStr = `
-		(import_spec path: (string_literal) @path)
+		(import_spec path: [(interpreted_string_literal) (raw_string_literal)] @path)
 		`
 	case LangPython:
+		// `from` imports are expanded by pythonFromImports
 		queryStr = `
-		(import_from_statement module_name: (dotted_name) @path)
 		(import_statement name: (dotted_name) @path)
+		(import_statement name: (aliased_import name: (dotted_name) @path))
+		(import_from_statement) @from
 		`
 	case LangRust:
+		// `use` trees are expanded by rustUsePaths, and `mod name;` declarations import `self::name`
 		queryStr = `
-		(use_declaration argument: (scoped_identifier) @path)
+		(use_declaration argument: (_) @use)
+		(mod_item name: (identifier) @mod) @item
 		`
 	case LangPHP:
+		// Grouped `use` declarations are expanded by phpUsePaths
 		queryStr = `
-		(namespace_use_clause (qualified_name) @path)
+		(namespace_use_declaration) @phpuse
+		`
+	case LangJava:
+		queryStr = `
+		(import_declaration) @path
+		`
+	case LangKotlin:
+		queryStr = `
+		(import_header) @path
 		`
 	}
 
@@ -119,18 +195,41 @@ func ParseImports(content []byte, lang Language) ([]string, error) {
 	qc := sitter.NewQueryCursor()
 	qc.Exec(q, root)
 
-	var imports []string
+	var imports []Import
+	add := func(n *sitter.Node, paths ...string) {
+		for _, p := range paths {
+			imports = append(imports, newImport(p, n))
+		}
+	}
 	for {
 		m, ok := qc.NextMatch()
 		if !ok {
 			break
 		}
+		m = qc.FilterPredicates(m, content)
 		for _, c := range m.Captures {
-			if c.Node != nil {
+			if c.Node == nil {
+				continue
+			}
+			switch q.CaptureNameForId(c.Index) {
+			case "from":
+				add(c.Node, pythonFromImports(c.Node, content)...)
+			case "use":
+				add(c.Node.Parent(), rustUsePaths(c.Node, content, "")...)
+			case "phpuse":
+				add(c.Node, phpUsePaths(c.Node, content)...)
+			case "mod":
+				if item := c.Node.Parent(); item.ChildByFieldName("body") == nil {
+					add(item, "self::"+string(content[c.Node.StartByte():c.Node.EndByte()]))
+				}
+			case "path":
 				text := string(content[c.Node.StartByte():c.Node.EndByte()])
 				// Clean quotes for some languages
 				text = strings.Trim(text, "\"'`")
-				imports = append(imports, text)
+				if lang == LangJava || lang == LangKotlin {
+					text = jvmImportPath(text)
+				}
+				add(c.Node, text)
 			}
 		}
 	}
@@ -138,101 +237,118 @@ func ParseImports(content []byte, lang Language) ([]string, error) {
 	return imports, nil
 }
 
-// ParseStepDefinitions extracts BDD step definitions from test files.
-// It looks for patterns like `Given("...")`, `Step("...")` depending on the language/framework.
-func ParseStepDefinitions(content []byte, lang Language) ([]StepDefFound, error) {
-	sl := getLanguage(lang)
-	if sl == nil {
-		return nil, nil
+// jvmImportPath returns the imported name of a Java or Kotlin import declaration,
+// e.g. `com.acme.domain.*` for `import com.acme.domain.*;` and `com.acme.util.Money.round` for
+// `import static com.acme.util.Money.round;`. Kotlin aliases (`as Name`) are dropped.
+func jvmImportPath(decl string) string {
+	fields := strings.Fields(strings.TrimSuffix(strings.TrimSpace(decl), ";"))
+	var path []string
+	for i, f := range fields {
+		if i == 0 && f == "import" || f == "static" && len(path) == 0 {
+			continue
+		}
+		if f == "as" {
+			break
+		}
+		path = append(path, f)
 	}
+	return strings.Join(path, "")
+}
 
-	parser := sitter.NewParser()
-	parser.SetLanguage(sl)
-	tree, _ := parser.ParseCtx(context.Background(), nil, content)
-	root := tree.RootNode()
+// pythonFromImports returns the names imported by a Python `from` statement, qualified by their module:
+// `from app.domain import user, order` imports `app.domain.user` and `app.domain.order`, and
+// `from . import x` imports `.x`. The imported names may be submodules or members of the module.
+// Wildcard imports return the module itself.
+func pythonFromImports(n *sitter.Node, content []byte) []string {
+	text := func(n *sitter.Node) string { return string(content[n.StartByte():n.EndByte()]) }
+	module := text(n.ChildByFieldName("module_name"))
 
-	// Queries for step definitions
-	// TODO: Add Go (Godog), Python (Behave), Rust (Cucumber), PHP (Behat)
-	var queryStr string
-	switch lang {
-	case LangTypeScript:
-		queryStr = `
-		(call_expression
-			function: (identifier) @keyword
-			arguments: (arguments
-				(string (string_fragment) @pattern)
-			)
-		)
-		`
-	case LangGo:
-		// Godog: ctx.Step(`^regex$`, handler)
-		// Or: suite.Step(`^regex$`, handler)
-		queryStr = `
-		(call_expression
-			function: (selector_expression field: (field_identifier) @method)
-			arguments: (argument_list
-				(raw_string_literal) @pattern
-			)
-			(#match? @method "^(Step|Given|When|Then)$")
-		)
-		`
-	case LangPython:
-		// Behave: @given("pattern")
-		queryStr = `
-		(decorated_definition
-			decorator: (decorator
-				call: (call
-					function: (identifier) @keyword
-					arguments: (argument_list (string) @pattern)
-				)
-			)
-			definition: (function_definition name: (identifier) @method)
-		)
-		`
-		// Rust and PHP would need specific framework queries. Leaving as TODO/Partial for now.
+	var imports []string
+	for i := 0; i < int(n.ChildCount()); i++ {
+		if n.FieldNameForChild(i) != "name" {
+			continue
+		}
+		name := n.Child(i)
+		if name.Type() == "aliased_import" {
+			name = name.ChildByFieldName("name")
+		}
+		if strings.HasSuffix(module, ".") {
+			imports = append(imports, module+text(name))
+		} else {
+			imports = append(imports, module+"."+text(name))
+		}
 	}
-
-	if queryStr == "" {
-		return nil, nil
+	if len(imports) == 0 {
+		imports = append(imports, module)
 	}
+	return imports
+}
 
-	q, err := sitter.NewQuery([]byte(queryStr), sl)
-	if err != nil {
-		return nil, err
+// rustUsePaths expands a Rust `use` tree into the full paths it imports:
+// `use crate::domain::{user::User, order}` imports `crate::domain::user::User` and `crate::domain::order`.
+// Aliases are dropped, and wildcard imports return the path of the module.
+func rustUsePaths(n *sitter.Node, content []byte, prefix string) []string {
+	join := func(path string) string {
+		path = strings.TrimPrefix(strings.Join(strings.Fields(path), ""), "::")
+		switch {
+		case prefix == "":
+			return path
+		case path == "self":
+			return prefix
+		}
+		return prefix + "::" + path
 	}
-	qc := sitter.NewQueryCursor()
-	qc.Exec(q, root)
-
-	var results []StepDefFound
+	text := func(n *sitter.Node) string { return string(content[n.StartByte():n.EndByte()]) }
 
-	for {
-		m, ok := qc.NextMatch()
-		if !ok {
-			break
+	switch n.Type() {
+	case "use_as_clause":
+		return []string{join(text(n.ChildByFieldName("path")))}
+	case "use_wildcard":
+		if n.NamedChildCount() == 0 {
+			return []string{prefix}
+		}
+		return []string{join(text(n.NamedChild(0)))}
+	case "scoped_use_list":
+		if path := n.ChildByFieldName("path"); path != nil {
+			prefix = join(text(path))
+		}
+		return rustUsePaths(n.ChildByFieldName("list"), content, prefix)
+	case "use_list":
+		var paths []string
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			paths = append(paths, rustUsePaths(n.NamedChild(i), content, prefix)...)
 		}
+		return paths
+	}
+	return []string{join(text(n))}
+}
 
-		var pattern, method string
-		var line int
+// phpUsePaths returns the fully qualified names imported by a PHP `use` declaration, without leading backslash:
+// `use App\Domain\{User\User, Invoice};` imports `App\Domain\User\User` and `App\Domain\Invoice`.
+// Aliases are dropped.
+func phpUsePaths(n *sitter.Node, content []byte) []string {
+	text := func(n *sitter.Node) string {
+		return strings.TrimPrefix(string(content[n.StartByte():n.EndByte()]), "\\")
+	}
 
-		for _, c := range m.Captures {
-			name := q.CaptureNameForId(c.Index)
-			if name == "pattern" {
-				pattern = string(content[c.Node.StartByte():c.Node.EndByte()])
-				pattern = strings.Trim(pattern, "\"`'")
-				line = int(c.Node.StartPoint().Row) + 1
-			} else if name == "method" {
-				method = string(content[c.Node.StartByte():c.Node.EndByte()])
+	var prefix string
+	var paths []string
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		child := n.NamedChild(i)
+		switch child.Type() {
+		case "namespace_name":
+			prefix = text(child) + "\\"
+		case "namespace_use_clause":
+			if child.NamedChildCount() > 0 {
+				paths = append(paths, text(child.NamedChild(0)))
+			}
+		case "namespace_use_group":
+			for j := 0; j < int(child.NamedChildCount()); j++ {
+				if clause := child.NamedChild(j); clause.NamedChildCount() > 0 {
+					paths = append(paths, prefix+text(clause.NamedChild(0)))
+				}
 			}
-		}
-
-		if pattern != "" {
-			results = append(results, StepDefFound{
-				Pattern:      pattern,
-				FunctionName: method,
-				Line:         line,
-			})
 		}
 	}
-
-	return results, nil
+	return paths
 }
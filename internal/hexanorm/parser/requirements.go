@@ -0,0 +1,191 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// RequirementDoc represents a requirement declared as code, in a Markdown file with YAML front matter
+// or in a YAML file.
+type RequirementDoc struct {
+	ID                 string   // The requirement ID, e.g. `REQ-42`.
+	Title              string   // The title, or the first Markdown heading.
+	Description        string   // The description, or the Markdown body.
+	Status             string   // The status, e.g. `approved`.
+	Priority           string   // The priority, e.g. `high`.
+	ExternalLink       string   // A link to the requirement in an external tracker.
+	AcceptanceCriteria []string // The acceptance criteria.
+	Features           []string // The names of the features the requirement defines.
+}
+
+// ParseRequirement parses a requirement file. Markdown files carry the requirement in their
+// `---`-delimited front matter, other files are read as YAML documents.
+// It returns nil if the file declares no `id`.
+func ParseRequirement(content []byte, markdown bool) (*RequirementDoc, error) {
+	yaml, body := content, ""
+	if markdown {
+		var ok bool
+		if yaml, body, ok = splitFrontMatter(content); !ok {
+			return nil, nil
+		}
+	}
+
+	fields, err := parseSimpleYAML(yaml)
+	if err != nil {
+		return nil, err
+	}
+	str := func(key string) string {
+		s, _ := fields[key].(string)
+		return s
+	}
+	list := func(key string) []string {
+		switch v := fields[key].(type) {
+		case []string:
+			return v
+		case string:
+			if v != "" {
+				return []string{v}
+			}
+		}
+		return nil
+	}
+
+	req := &RequirementDoc{
+		ID:                 str("id"),
+		Title:              str("title"),
+		Description:        str("description"),
+		Status:             str("status"),
+		Priority:           str("priority"),
+		ExternalLink:       str("external_link"),
+		AcceptanceCriteria: list("acceptance_criteria"),
+		Features:           list("features"),
+	}
+	if req.ID == "" {
+		return nil, nil
+	}
+
+	body = strings.TrimSpace(body)
+	if req.Title == "" {
+		for _, line := range strings.Split(body, "\n") {
+			if strings.HasPrefix(line, "# ") {
+				req.Title = strings.TrimSpace(strings.TrimPrefix(line, "# "))
+				break
+			}
+		}
+	}
+	if req.Description == "" {
+		req.Description = body
+	}
+	return req, nil
+}
+
+// splitFrontMatter returns the YAML front matter and the body of a Markdown document.
+func splitFrontMatter(content []byte) ([]byte, string, bool) {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return nil, "", false
+	}
+	rest := text[len("---\n"):]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return nil, "", false
+	}
+	body := rest[end+len("\n---"):]
+	if i := strings.Index(body, "\n"); i >= 0 {
+		body = body[i+1:]
+	} else {
+		body = ""
+	}
+	return []byte(rest[:end+1]), body, true
+}
+
+// parseSimpleYAML parses the subset of YAML used by front matter: a mapping of keys to scalars,
+// `[a, b]` flow lists, `- item` block lists, and `|` or `>` block scalars. Values are strings or string slices.
+func parseSimpleYAML(content []byte) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+
+	var key string   // Key of the pending block list or block scalar
+	var block string // Block scalar style: "|" or ">"
+	var lines []string
+	flush := func() {
+		if key != "" && block != "" {
+			sep := "\n"
+			if block == ">" {
+				sep = " "
+			}
+			fields[key] = strings.TrimSpace(strings.Join(lines, sep))
+		}
+		key, block, lines = "", "", nil
+	}
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		raw := scanner.Text()
+		trimmed := strings.TrimSpace(raw)
+		indented := raw != "" && (raw[0] == ' ' || raw[0] == '\t')
+
+		if block != "" && (indented || trimmed == "") {
+			lines = append(lines, trimmed)
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if key != "" && block == "" && strings.HasPrefix(trimmed, "- ") {
+			items, _ := fields[key].([]string)
+			fields[key] = append(items, unquote(stripComment(strings.TrimSpace(trimmed[2:]))))
+			continue
+		}
+		if indented {
+			continue // Nested mappings are not supported
+		}
+
+		flush()
+		name, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected `key: value`", lineNum)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		switch {
+		case value == "":
+			key = name
+			fields[name] = []string{}
+		case value == "|" || value == ">" || value == "|-" || value == ">-":
+			key, block = name, value[:1]
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			items := []string{}
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = unquote(strings.TrimSpace(item)); item != "" {
+					items = append(items, item)
+				}
+			}
+			fields[name] = items
+		default:
+			fields[name] = unquote(stripComment(value))
+		}
+	}
+	flush()
+	return fields, scanner.Err()
+}
+
+// stripComment removes a trailing ` # comment` from an unquoted scalar.
+func stripComment(value string) string {
+	if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'") {
+		return value
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		return strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
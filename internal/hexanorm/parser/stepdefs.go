@@ -0,0 +1,377 @@
+package parser
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// stepKeywords are the names, in lower case, of the functions, decorators, attributes and annotations registering steps.
+var stepKeywords = []string{"given", "when", "then", "and", "but", "step", "definestep"}
+
+// behatAnnotation matches a Behat step annotation in a docblock line, e.g. `* @Given /^I have (\d+) apples$/`.
+var behatAnnotation = regexp.MustCompile(`^\s*\*?\s*@(Given|When|Then)\s+(.+?)\s*$`)
+
+// ParseStepDefinitions extracts BDD step definitions from test files:
+//   - Go (godog): `ctx.Step(pattern, handler)` and `Given`/`When`/`Then` on a ScenarioContext or Suite
+//   - TypeScript (cucumber-js): `Given(pattern, handler)`, `When`, `Then`, `defineStep`
+//   - Python (behave, pytest-bdd): `@given(pattern)` decorators, including `parsers.parse(pattern)` arguments
+//   - Rust (cucumber-rs): `#[given(pattern)]`, `#[given(expr = ...)]` and `#[given(regex = ...)]` attributes
+//   - PHP (Behat): `@Given pattern` docblock annotations and `#[Given(pattern)]` attributes
+//   - Java and Kotlin (Cucumber-JVM): `@Given(pattern)` annotations, and `@When`, `@Then`, `@And`, `@But`
+//
+// FunctionName and the line range are those of the handler: the named function when it is declared in the file,
+// or the anonymous function passed as handler.
+func ParseStepDefinitions(content []byte, lang Language) ([]StepDefFound, error) {
+	sl := getLanguage(lang)
+	if sl == nil {
+		return nil, nil
+	}
+
+	parser := sitter.NewParser()
+	parser.SetLanguage(sl)
+	tree, err := parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &stepDefCollector{content: content, lang: lang, functions: make(map[string]*sitter.Node)}
+	c.indexFunctions(tree.RootNode())
+	c.walk(tree.RootNode())
+	return c.steps, nil
+}
+
+// stepDefCollector accumulates step definitions while walking a syntax tree.
+type stepDefCollector struct {
+	content   []byte
+	lang      Language
+	functions map[string]*sitter.Node // Top-level functions and methods by name, to locate named handlers.
+	steps     []StepDefFound
+}
+
+// indexFunctions records the functions and methods declared in the file by name.
+func (c *stepDefCollector) indexFunctions(n *sitter.Node) {
+	switch n.Type() {
+	case "function_declaration", "method_declaration":
+		if name := c.text(n.ChildByFieldName("name")); name != "" {
+			c.functions[name] = n
+		}
+	}
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		c.indexFunctions(n.NamedChild(i))
+	}
+}
+
+func (c *stepDefCollector) walk(n *sitter.Node) {
+	switch c.lang {
+	case LangGo:
+		if n.Type() == "call_expression" {
+			c.visitGoCall(n)
+		}
+	case LangTypeScript, LangTSX, LangJavaScript:
+		if n.Type() == "call_expression" {
+			c.visitTSCall(n)
+		}
+	case LangPython:
+		if n.Type() == "decorated_definition" {
+			c.visitPythonDefinition(n)
+		}
+	case LangRust:
+		if n.Type() == "function_item" {
+			c.visitRustFunction(n)
+		}
+	case LangPHP:
+		if n.Type() == "method_declaration" || n.Type() == "function_definition" {
+			c.visitPHPFunction(n)
+		}
+	case LangJava:
+		if n.Type() == "method_declaration" {
+			c.visitJVMMethod(n, c.text(n.ChildByFieldName("name")))
+		}
+	case LangKotlin:
+		if n.Type() == "function_declaration" {
+			c.visitJVMMethod(n, c.text(c.firstOfType(n, "simple_identifier")))
+		}
+	}
+
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		c.walk(n.NamedChild(i))
+	}
+}
+
+// visitGoCall recognizes godog registrations: `ctx.Step(`^pattern$`, handler)` or `ctx.Given("^pattern$", s.handler)`.
+func (c *stepDefCollector) visitGoCall(n *sitter.Node) {
+	fn := n.ChildByFieldName("function")
+	if fn == nil || fn.Type() != "selector_expression" || !isStepKeyword(c.text(fn.ChildByFieldName("field"))) {
+		return
+	}
+	args := n.ChildByFieldName("arguments")
+	if args == nil || args.NamedChildCount() < 2 {
+		return
+	}
+
+	var pattern string
+	switch first := args.NamedChild(0); first.Type() {
+	case "raw_string_literal":
+		pattern = strings.Trim(c.text(first), "`")
+	case "interpreted_string_literal":
+		unquoted, err := strconv.Unquote(c.text(first))
+		if err != nil {
+			return
+		}
+		pattern = unquoted
+	default:
+		return
+	}
+	c.addHandler(n, pattern, args.NamedChild(1))
+}
+
+// visitTSCall recognizes cucumber-js registrations: `Given('pattern', function () {...})` or `When(/^regex$/, handler)`.
+func (c *stepDefCollector) visitTSCall(n *sitter.Node) {
+	fn := n.ChildByFieldName("function")
+	if fn == nil || fn.Type() != "identifier" || !isStepKeyword(c.text(fn)) {
+		return
+	}
+	args := n.ChildByFieldName("arguments")
+	if args == nil || args.NamedChildCount() < 2 {
+		return
+	}
+
+	var pattern string
+	switch first := args.NamedChild(0); first.Type() {
+	case "string", "template_string":
+		pattern = strings.Trim(c.text(first), "'\"`")
+	case "regex":
+		pattern = c.text(first.ChildByFieldName("pattern"))
+	default:
+		return
+	}
+	c.addHandler(n, pattern, args.NamedChild(int(args.NamedChildCount())-1))
+}
+
+// addHandler adds a step definition implemented by the handler expression passed to the registering call.
+func (c *stepDefCollector) addHandler(call *sitter.Node, pattern string, handler *sitter.Node) {
+	var name string
+	span := handler
+	switch handler.Type() {
+	case "identifier":
+		name = c.text(handler)
+	case "selector_expression":
+		name = c.text(handler.ChildByFieldName("field"))
+	case "member_expression":
+		name = c.text(handler.ChildByFieldName("property"))
+	case "function_expression", "function":
+		name = c.text(handler.ChildByFieldName("name"))
+	case "func_literal", "arrow_function":
+	default:
+		span = call
+	}
+	if decl, ok := c.functions[name]; ok && name != "" && span != call {
+		span = decl
+	}
+	c.add(pattern, name, span)
+}
+
+// visitPythonDefinition recognizes behave and pytest-bdd decorators: `@given('pattern')`, `@when(parsers.parse('pattern'))`.
+func (c *stepDefCollector) visitPythonDefinition(n *sitter.Node) {
+	def := n.ChildByFieldName("definition")
+	if def == nil || def.Type() != "function_definition" {
+		return
+	}
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		dec := n.NamedChild(i)
+		if dec.Type() != "decorator" || dec.NamedChildCount() == 0 {
+			continue
+		}
+		call := dec.NamedChild(0)
+		if call.Type() != "call" || !isStepKeyword(c.lastName(call.ChildByFieldName("function"))) {
+			continue
+		}
+		if pattern, ok := c.pythonPattern(call.ChildByFieldName("arguments")); ok {
+			c.add(pattern, c.text(def.ChildByFieldName("name")), def)
+		}
+	}
+}
+
+// pythonPattern returns the first positional argument of a decorator if it is a string,
+// or a string wrapped in a pytest-bdd parser such as `parsers.parse('...')`.
+func (c *stepDefCollector) pythonPattern(args *sitter.Node) (string, bool) {
+	if args == nil || args.NamedChildCount() == 0 {
+		return "", false
+	}
+	switch first := args.NamedChild(0); first.Type() {
+	case "string":
+		return c.pythonString(first), true
+	case "call":
+		return c.pythonPattern(first.ChildByFieldName("arguments"))
+	}
+	return "", false
+}
+
+// pythonString returns the content of a string literal, without its quotes and prefixes such as `u` or `r`.
+func (c *stepDefCollector) pythonString(n *sitter.Node) string {
+	var sb strings.Builder
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		if part := n.NamedChild(i); part.Type() == "string_content" || part.Type() == "escape_sequence" {
+			sb.WriteString(c.text(part))
+		}
+	}
+	return sb.String()
+}
+
+// visitRustFunction recognizes cucumber-rs attributes: `#[given("pattern")]`, `#[when(expr = "...")]`, `#[then(regex = r"...")]`.
+func (c *stepDefCollector) visitRustFunction(n *sitter.Node) {
+	for prev := n.PrevNamedSibling(); prev != nil && (prev.Type() == "attribute_item" || prev.Type() == "line_comment"); prev = prev.PrevNamedSibling() {
+		if prev.Type() != "attribute_item" || prev.NamedChildCount() == 0 {
+			continue
+		}
+		attr := prev.NamedChild(0)
+		if attr.NamedChildCount() == 0 || !isStepKeyword(c.lastName(attr.NamedChild(0))) {
+			continue
+		}
+		args := attr.ChildByFieldName("arguments")
+		if args == nil {
+			continue
+		}
+		for i := 0; i < int(args.NamedChildCount()); i++ {
+			if lit := args.NamedChild(i); lit.Type() == "string_literal" || lit.Type() == "raw_string_literal" {
+				c.add(c.text(c.firstOfType(lit, "string_content")), c.text(n.ChildByFieldName("name")), n)
+				break
+			}
+		}
+	}
+}
+
+// visitPHPFunction recognizes Behat step annotations in the docblock of a method, and PHP 8 `#[Given('...')]` attributes.
+func (c *stepDefCollector) visitPHPFunction(n *sitter.Node) {
+	name := c.text(n.ChildByFieldName("name"))
+	if prev := n.PrevNamedSibling(); prev != nil && prev.Type() == "comment" && strings.HasPrefix(c.text(prev), "/**") {
+		for _, l := range strings.Split(c.text(prev), "\n") {
+			if m := behatAnnotation.FindStringSubmatch(strings.TrimSuffix(l, "*/")); m != nil {
+				c.add(behatPattern(m[2]), name, n)
+			}
+		}
+	}
+
+	attrs := n.ChildByFieldName("attributes")
+	if attrs == nil {
+		return
+	}
+	for _, attr := range c.descendants(attrs, "attribute") {
+		if attr.NamedChildCount() == 0 || !isStepKeyword(c.lastName(attr.NamedChild(0))) {
+			continue
+		}
+		params := attr.ChildByFieldName("parameters")
+		if params == nil || params.NamedChildCount() == 0 {
+			continue
+		}
+		arg := params.NamedChild(0)
+		if arg.NamedChildCount() > 0 {
+			arg = arg.NamedChild(0)
+		}
+		if arg.Type() == "string" || arg.Type() == "encapsed_string" {
+			c.add(behatPattern(strings.Trim(c.text(arg), "'\"")), name, n)
+		}
+	}
+}
+
+// visitJVMMethod recognizes Cucumber-JVM annotations on a Java or Kotlin method: `@Given("pattern")`, `@When(value = "...")`.
+func (c *stepDefCollector) visitJVMMethod(n *sitter.Node, name string) {
+	modifiers := c.firstOfType(n, "modifiers")
+	if modifiers == nil {
+		return
+	}
+	for _, annotation := range c.descendants(modifiers, "annotation") {
+		var keyword string
+		var args *sitter.Node
+		if c.lang == LangJava {
+			keyword, args = c.text(annotation.ChildByFieldName("name")), annotation.ChildByFieldName("arguments")
+		} else if call := c.firstOfType(annotation, "constructor_invocation"); call != nil {
+			keyword, args = c.text(c.firstOfType(call, "user_type")), c.firstOfType(call, "value_arguments")
+		}
+		if !isStepKeyword(lastSegment(keyword)) || args == nil {
+			continue
+		}
+		if lit := c.descendants(args, "string_literal"); len(lit) > 0 {
+			c.add(jvmString(c.text(lit[0])), name, n)
+		}
+	}
+}
+
+// jvmString returns the value of a Java or Kotlin string literal, unescaping it when possible.
+func jvmString(lit string) string {
+	if strings.HasPrefix(lit, `"""`) {
+		return strings.Trim(lit, `"`)
+	}
+	if unquoted, err := strconv.Unquote(lit); err == nil {
+		return unquoted
+	}
+	return strings.Trim(lit, `"`)
+}
+
+// behatPattern strips the delimiters and flags of a Behat regex pattern, e.g. `/^I have (\d+) apples$/i`.
+// Turnip patterns such as `I have :count apples` are returned as written.
+func behatPattern(p string) string {
+	if len(p) > 1 && p[0] == '/' {
+		if end := strings.LastIndex(p, "/"); end > 0 {
+			return p[1:end]
+		}
+	}
+	return p
+}
+
+func (c *stepDefCollector) add(pattern, name string, span *sitter.Node) {
+	if pattern == "" {
+		return
+	}
+	c.steps = append(c.steps, StepDefFound{Pattern: pattern, FunctionName: name, Line: line(span), EndLine: endLine(span)})
+}
+
+// lastName returns the last segment of a possibly qualified name: `given` for `given`, `behave.given` or `cucumber::given`.
+func (c *stepDefCollector) lastName(n *sitter.Node) string {
+	return lastSegment(c.text(n))
+}
+
+// lastSegment returns the last segment of a name qualified with `.`, `::` or `\`.
+func lastSegment(name string) string {
+	if i := strings.LastIndexAny(name, ".:\\"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func (c *stepDefCollector) firstOfType(n *sitter.Node, typ string) *sitter.Node {
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		if child := n.NamedChild(i); child.Type() == typ {
+			return child
+		}
+	}
+	return nil
+}
+
+func (c *stepDefCollector) descendants(n *sitter.Node, typ string) []*sitter.Node {
+	var found []*sitter.Node
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		child := n.NamedChild(i)
+		if child.Type() == typ {
+			found = append(found, child)
+			continue
+		}
+		found = append(found, c.descendants(child, typ)...)
+	}
+	return found
+}
+
+func (c *stepDefCollector) text(n *sitter.Node) string {
+	if n == nil {
+		return ""
+	}
+	return string(c.content[n.StartByte():n.EndByte()])
+}
+
+func isStepKeyword(name string) bool {
+	return contains(stepKeywords, strings.ToLower(name))
+}
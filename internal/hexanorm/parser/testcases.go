@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// TestCase represents a unit or integration test found in a test file.
+type TestCase struct {
+	Name      string // The name of the test, prefixed by its enclosing `describe` blocks or test class.
+	Framework string // The test framework, e.g. `go`, `jest`, `pytest` or `rust`.
+	StartLine int    // The line number where the test starts.
+	EndLine   int    // The line number where the test ends.
+}
+
+var (
+	goTestName     = regexp.MustCompile(`^Test([A-Z0-9_].*)?$`)
+	rustTestAttr   = regexp.MustCompile(`^#\[(\w+::)*test(\(.*\))?\]$`)
+	jsTestFile     = regexp.MustCompile(`(\.(test|spec)\.[cm]?[jt]sx?$)|(^|/)__tests__/`)
+	pythonTestFile = regexp.MustCompile(`(^|/)(test_[^/]*|[^/]*_test)\.py$`)
+)
+
+// IsTestFile reports whether the file holds tests according to the conventions of its language:
+// Go `_test.go` files, Jest/Vitest `.test.ts`/`.spec.ts` files and `__tests__` directories,
+// pytest `test_*.py`/`*_test.py` files, and any Rust file (tests live next to the code).
+func IsTestFile(path string, lang Language) bool {
+	slashed := filepath.ToSlash(path)
+	switch lang {
+	case LangGo:
+		return strings.HasSuffix(slashed, "_test.go")
+	case LangTypeScript, LangTSX, LangJavaScript:
+		return jsTestFile.MatchString(slashed)
+	case LangPython:
+		return pythonTestFile.MatchString(slashed)
+	case LangRust:
+		return true
+	}
+	return false
+}
+
+// ParseTests extracts the tests declared in a test file: Go `TestXxx` functions, Jest/Vitest `it`/`test` blocks,
+// pytest `test_*` functions and methods of `Test*` classes, and Rust `#[test]` functions.
+func ParseTests(content []byte, path string, lang Language) ([]TestCase, error) {
+	sl := getLanguage(lang)
+	if sl == nil || !IsTestFile(path, lang) {
+		return nil, nil
+	}
+
+	parser := sitter.NewParser()
+	parser.SetLanguage(sl)
+	tree, err := parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &testCollector{content: content, lang: lang}
+	c.walk(tree.RootNode(), nil)
+	return c.tests, nil
+}
+
+// testCollector accumulates tests while walking a syntax tree.
+type testCollector struct {
+	content []byte
+	lang    Language
+	tests   []TestCase
+}
+
+// walk visits the tree, keeping track of the enclosing describe blocks or test classes.
+func (c *testCollector) walk(n *sitter.Node, scope []string) {
+	switch c.lang {
+	case LangGo:
+		if n.Type() == "function_declaration" {
+			if name := c.text(n.ChildByFieldName("name")); goTestName.MatchString(name) && name != "TestMain" {
+				c.add(n, name, "go")
+			}
+			return
+		}
+	case LangTypeScript, LangTSX, LangJavaScript:
+		if n.Type() == "call_expression" {
+			fn := c.baseCallee(n.ChildByFieldName("function"))
+			title := c.firstString(n.ChildByFieldName("arguments"))
+			switch {
+			case title == "":
+			case fn == "describe" || fn == "suite":
+				scope = append(scope, title)
+			case fn == "it" || fn == "test":
+				c.add(n, strings.Join(append(append([]string{}, scope...), title), " > "), "jest")
+				return
+			}
+		}
+	case LangPython:
+		switch n.Type() {
+		case "class_definition":
+			name := c.text(n.ChildByFieldName("name"))
+			if !strings.HasPrefix(name, "Test") {
+				return
+			}
+			scope = append(scope, name)
+		case "function_definition":
+			if name := c.text(n.ChildByFieldName("name")); strings.HasPrefix(name, "test") {
+				c.add(n, strings.Join(append(append([]string{}, scope...), name), "."), "pytest")
+			}
+			return
+		}
+	case LangRust:
+		if n.Type() == "function_item" && c.hasTestAttribute(n) {
+			c.add(n, c.text(n.ChildByFieldName("name")), "rust")
+			return
+		}
+	}
+
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		c.walk(n.NamedChild(i), scope)
+	}
+}
+
+func (c *testCollector) add(n *sitter.Node, name, framework string) {
+	c.tests = append(c.tests, TestCase{Name: name, Framework: framework, StartLine: line(n), EndLine: endLine(n)})
+}
+
+// baseCallee returns the name at the root of a callee: `it` for `it`, `it.skip` and `it.each([...])`.
+func (c *testCollector) baseCallee(n *sitter.Node) string {
+	if n == nil {
+		return ""
+	}
+	switch n.Type() {
+	case "identifier":
+		return c.text(n)
+	case "member_expression":
+		return c.baseCallee(n.ChildByFieldName("object"))
+	case "call_expression":
+		return c.baseCallee(n.ChildByFieldName("function"))
+	}
+	return ""
+}
+
+// firstString returns the content of the first argument if it is a string literal.
+func (c *testCollector) firstString(args *sitter.Node) string {
+	if args == nil || args.NamedChildCount() == 0 {
+		return ""
+	}
+	first := args.NamedChild(0)
+	if first.Type() != "string" && first.Type() != "template_string" {
+		return ""
+	}
+	return strings.Trim(c.text(first), "'\"`")
+}
+
+// hasTestAttribute reports whether a Rust function is preceded by a `#[test]`-like attribute, e.g. `#[tokio::test]`.
+func (c *testCollector) hasTestAttribute(fn *sitter.Node) bool {
+	for prev := fn.PrevNamedSibling(); prev != nil && prev.Type() == "attribute_item"; prev = prev.PrevNamedSibling() {
+		if rustTestAttr.MatchString(strings.Join(strings.Fields(c.text(prev)), "")) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *testCollector) text(n *sitter.Node) string {
+	if n == nil {
+		return ""
+	}
+	return string(c.content[n.StartByte():n.EndByte()])
+}
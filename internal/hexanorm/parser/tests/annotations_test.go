@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/parser"
+)
+
+func TestParseAnnotations(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    []parser.Annotation
+	}{
+		{
+			name: "go",
+			path: "internal/billing/invoice.go",
+			content: `// @hexanorm:layer application
+
+package billing
+
+// Issue creates an invoice.
+// @hexanorm:implements REQ-42, REQ-43
+func Issue() {}`,
+			want: []parser.Annotation{
+				{Directive: "layer", Args: []string{"application"}, Params: map[string]string{}, Line: 1},
+				{Directive: "implements", Args: []string{"REQ-42", "REQ-43"}, Params: map[string]string{}, Line: 6, TargetLine: 7},
+			},
+		},
+		{
+			name: "typescript",
+			path: "src/domain/User.ts",
+			content: `/**
+ * @hexanorm:ignore ARCH_LAYER_VIOLATION reason="legacy, tracked in REQ-7"
+ */
+export class User {}`,
+			want: []parser.Annotation{
+				{Directive: "ignore", Args: []string{"ARCH_LAYER_VIOLATION"}, Params: map[string]string{"reason": "legacy, tracked in REQ-7"}, Line: 2, TargetLine: 4},
+			},
+		},
+		{
+			name:    "python",
+			path:    "app/service.py",
+			content: "import os\n\n# @hexanorm:ignore reason=generated\n",
+			want: []parser.Annotation{
+				{Directive: "ignore", Params: map[string]string{"reason": "generated"}, Line: 3},
+			},
+		},
+		{
+			name:    "python decorator",
+			path:    "app/routes.py",
+			content: "# @hexanorm:implements REQ-9\n@app.route(\"/\")\ndef index():\n    pass\n",
+			want: []parser.Annotation{
+				{Directive: "implements", Args: []string{"REQ-9"}, Params: map[string]string{}, Line: 1, TargetLine: 3},
+			},
+		},
+		{
+			name:    "rust",
+			path:    "src/lib.rs",
+			content: "/* @hexanorm:layer domain */\npub struct Money;",
+			want: []parser.Annotation{
+				{Directive: "layer", Args: []string{"domain"}, Params: map[string]string{}, Line: 1, TargetLine: 2},
+			},
+		},
+		{
+			name:    "kotlin",
+			path:    "src/main/kotlin/App.kt",
+			content: "// a regular comment\nfun main() {}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.ParseAnnotations([]byte(tt.content), parser.DetectLanguage(tt.path))
+			if err != nil {
+				t.Fatalf("ParseAnnotations failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/parser"
+)
+
+func TestParseGherkin(t *testing.T) {
+	content := `@billing
+Feature: Invoicing
+  Invoices are issued at checkout.
+
+  Background:
+    Given a customer "alice"
+
+  Scenario: Issue an invoice
+    When she checks out with:
+      | item  | price |
+      | book  | 10    |
+    Then the invoice reads:
+      """
+      Total: 10 \| EUR
+      """
+
+  Rule: VAT
+
+    Background:
+      Given VAT is enabled
+
+    @REQ-7
+    Scenario Outline: Apply <rate> VAT
+      When she buys for <amount>
+      Then she pays <total>
+
+      @eu
+      Examples:
+        | rate | amount | total |
+        | 21%  | 100    | 121   |
+        | 10%  | 10     | 11    |
+`
+	feat, err := parser.ParseGherkin([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feat.Name != "Invoicing" || !reflect.DeepEqual(feat.Tags, []string{"@billing"}) {
+		t.Errorf("Unexpected feature %q with tags %v", feat.Name, feat.Tags)
+	}
+	if len(feat.Scenarios) != 3 {
+		t.Fatalf("Expected 3 scenarios, got %d", len(feat.Scenarios))
+	}
+
+	issue := feat.Scenarios[0]
+	if !reflect.DeepEqual(issue.Steps, []string{`Given a customer "alice"`, "When she checks out with:", "Then the invoice reads:"}) {
+		t.Errorf("Expected background steps first, got %v", issue.Steps)
+	}
+	if table := issue.StepDetails[1].DataTable; !reflect.DeepEqual(table, [][]string{{"item", "price"}, {"book", "10"}}) {
+		t.Errorf("Unexpected data table %v", table)
+	}
+	if doc := issue.StepDetails[2].DocString; doc != `Total: 10 \| EUR` {
+		t.Errorf("Unexpected doc string %q", doc)
+	}
+
+	vat := feat.Scenarios[2]
+	if vat.Name != "Apply 10% VAT" || vat.Rule != "VAT" || vat.Example != 2 || vat.Line != 31 {
+		t.Errorf("Unexpected expanded outline %+v", vat)
+	}
+	if !reflect.DeepEqual(vat.Steps, []string{`Given a customer "alice"`, "Given VAT is enabled", "When she buys for 10", "Then she pays 11"}) {
+		t.Errorf("Unexpected expanded steps %v", vat.Steps)
+	}
+	if !reflect.DeepEqual(vat.Tags, []string{"@billing", "@REQ-7", "@eu"}) {
+		t.Errorf("Expected inherited tags, got %v", vat.Tags)
+	}
+}
+
+func TestParseGherkinLanguage(t *testing.T) {
+	content := `# language: fr
+Fonctionnalité: Facturation
+  Scénario: Émettre une facture
+    Etant donné qu'un client existe
+    Quand il paie
+    Alors une facture est émise
+`
+	feat, err := parser.ParseGherkin([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feat.Language != "fr" || len(feat.Scenarios) != 1 {
+		t.Fatalf("Expected 1 French scenario, got %+v", feat)
+	}
+	texts := []string{}
+	for _, s := range feat.Scenarios[0].StepDetails {
+		texts = append(texts, s.Text)
+	}
+	if !reflect.DeepEqual(texts, []string{"un client existe", "il paie", "une facture est émise"}) {
+		t.Errorf("Unexpected step texts %v", texts)
+	}
+}
+
+func TestParseGherkinDialects(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		language string
+		steps    []string
+		warned   bool
+	}{
+		{
+			name:     "official dialect",
+			content:  "# language: sv\nEgenskap: Fakturering\n  Scenario: Skicka en faktura\n    Givet en kund\n    När hon betalar\n",
+			language: "sv",
+			steps:    []string{"Givet en kund", "När hon betalar"},
+		},
+		{
+			name:     "unknown region of a known language",
+			content:  "# language: pt-BR\nFuncionalidade: Faturamento\n  Cenário: Emitir uma fatura\n    Dado um cliente\n",
+			language: "pt",
+			steps:    []string{"Dado um cliente"},
+			warned:   true,
+		},
+		{
+			name:     "unknown language",
+			content:  "# language: xx\nFeature: Invoicing\n  Scenario: Issue an invoice\n    Given a customer\n",
+			language: "en",
+			steps:    []string{"Given a customer"},
+			warned:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feat, err := parser.ParseGherkin([]byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if feat.Language != tt.language || len(feat.Scenarios) != 1 {
+				t.Fatalf("Expected 1 scenario in %q, got %+v", tt.language, feat)
+			}
+			if !reflect.DeepEqual(feat.Scenarios[0].Steps, tt.steps) {
+				t.Errorf("Expected steps %q, got %q", tt.steps, feat.Scenarios[0].Steps)
+			}
+			if got := len(feat.Warnings) > 0; got != tt.warned {
+				t.Errorf("Expected a warning: %v, got %v", tt.warned, feat.Warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/parser"
+)
+
+func TestParseImports(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    []string
+	}{
+		{
+			name: "javascript",
+			path: "src/server.mjs",
+			content: `import a from './a';
+export * from './b';
+const c = require('./c');
+const d = await import('./d');
+const e = require(` + "`./e/${name}`" + `);
+module.exports = { f: require(` + "`./f`" + `) };`,
+			want: []string{"./a", "./b", "./c", "./d", "./f"},
+		},
+		{
+			name: "tsx",
+			path: "src/App.tsx",
+			content: `import { Button } from './Button';
+export const App = () => <Button onClick={() => import('./lazy')}>Hi</Button>;`,
+			want: []string{"./Button", "./lazy"},
+		},
+		{
+			name:    "typescript import require",
+			path:    "src/legacy.ts",
+			content: `import fs = require('fs');`,
+			want:    []string{"fs"},
+		},
+		{
+			name: "go",
+			path: "internal/billing/service.go",
+			content: `package billing
+
+import (
+	"fmt"
+	db ` + "`example.com/shop/internal/infrastructure/db`" + `
+)`,
+			want: []string{"fmt", "example.com/shop/internal/infrastructure/db"},
+		},
+		{
+			name: "python",
+			path: "src/app/service.py",
+			content: `import os, app.infra.db as db
+from . import user, order as o
+from ..domain.user import User
+from .models import *`,
+			want: []string{"os", "app.infra.db", ".user", ".order", "..domain.user.User", ".models"},
+		},
+		{
+			name: "rust",
+			path: "src/lib.rs",
+			content: `use crate::domain::{user::User, order, self as d};
+use super::port::Repo as R;
+use std::{collections::{HashMap, HashSet}, fmt};
+pub mod service;
+mod inline { fn x() {} }`,
+			want: []string{
+				"crate::domain::user::User", "crate::domain::order", "crate::domain", "super::port::Repo",
+				"std::collections::HashMap", "std::collections::HashSet", "std::fmt", "self::service",
+			},
+		},
+		{
+			name: "java",
+			path: "src/main/java/com/acme/App.java",
+			content: `import com.acme.domain.*;
+import static com.acme.util.Money.round;`,
+			want: []string{"com.acme.domain.*", "com.acme.util.Money.round"},
+		},
+		{
+			name: "php",
+			path: "src/Application/Service.php",
+			content: `<?php
+use App\Domain\User\User;
+use App\Infrastructure\{Db, Mail\Mailer as M};
+use function App\Support\helper;
+use \Countable;`,
+			want: []string{
+				"App\\Domain\\User\\User", "App\\Infrastructure\\Db", "App\\Infrastructure\\Mail\\Mailer",
+				"App\\Support\\helper", "Countable",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.ParseImports([]byte(tt.content), parser.DetectLanguage(tt.path))
+			if err != nil {
+				t.Fatalf("ParseImports failed: %v", err)
+			}
+			paths := make([]string, len(got))
+			for i, imp := range got {
+				paths[i] = imp.Path
+			}
+			if !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, paths)
+			}
+		})
+	}
+}
+
+func TestImportPositions(t *testing.T) {
+	content := `package billing
+
+import (
+	"fmt"
+
+	"example.com/shop/internal/infrastructure/db"
+)`
+	got, err := parser.ParseImports([]byte(content), parser.LangGo)
+	if err != nil {
+		t.Fatalf("ParseImports failed: %v", err)
+	}
+	want := []parser.Import{
+		{Path: "fmt", Line: 4, Column: 2, EndLine: 4, EndColumn: 7},
+		{Path: "example.com/shop/internal/infrastructure/db", Line: 6, Column: 2, EndLine: 6, EndColumn: 47},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/parser"
+)
+
+func TestParseStepDefinitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		lang    parser.Language
+		content string
+		want    []parser.StepDefFound
+	}{
+		{
+			name: "godog",
+			lang: parser.LangGo,
+			content: `package steps
+
+func iHave(n int) error { return nil }
+
+func InitializeScenario(ctx *godog.ScenarioContext) {
+	ctx.Step(` + "`^I have (\\d+) cukes$`" + `, iHave)
+	ctx.Then("^I eat \\d+$", s.eat)
+	ctx.When(` + "`^done$`" + `, func() error {
+		return nil
+	})
+}`,
+			want: []parser.StepDefFound{
+				{Pattern: `^I have (\d+) cukes$`, FunctionName: "iHave", Line: 3, EndLine: 3},
+				{Pattern: `^I eat \d+$`, FunctionName: "eat", Line: 7, EndLine: 7},
+				{Pattern: `^done$`, Line: 8, EndLine: 10},
+			},
+		},
+		{
+			name: "cucumber-js",
+			lang: parser.LangTypeScript,
+			content: `Given('I have {int} cukes', function (n: number) {});
+When(/^I eat (\d+)$/, async (n) => {});
+describe('not a step', () => { it('works', () => {}); });`,
+			want: []parser.StepDefFound{
+				{Pattern: "I have {int} cukes", Line: 1, EndLine: 1},
+				{Pattern: `^I eat (\d+)$`, Line: 2, EndLine: 2},
+			},
+		},
+		{
+			name: "behave and pytest-bdd",
+			lang: parser.LangPython,
+			content: `@given(u'I have {n:d} cukes')
+def step_impl(context, n):
+    pass
+
+@when(parsers.parse("I eat {n:d}"), target_fixture="eaten")
+def eat(n):
+    pass`,
+			want: []parser.StepDefFound{
+				{Pattern: "I have {n:d} cukes", FunctionName: "step_impl", Line: 2, EndLine: 3},
+				{Pattern: "I eat {n:d}", FunctionName: "eat", Line: 6, EndLine: 7},
+			},
+		},
+		{
+			name: "cucumber-rs",
+			lang: parser.LangRust,
+			content: `#[given(expr = "I have {int} cukes")]
+async fn have(w: &mut World, n: u32) {}
+
+#[when(regex = r"^I eat (\d+)$")]
+#[allow(unused)]
+fn eat(w: &mut World) {}`,
+			want: []parser.StepDefFound{
+				{Pattern: "I have {int} cukes", FunctionName: "have", Line: 2, EndLine: 2},
+				{Pattern: `^I eat (\d+)$`, FunctionName: "eat", Line: 6, EndLine: 6},
+			},
+		},
+		{
+			name: "behat",
+			lang: parser.LangPHP,
+			content: `<?php
+class FeatureContext implements Context {
+    /**
+     * @Given /^I have (\d+) cukes$/
+     */
+    public function iHave($n) {}
+
+    #[When('I eat :n')]
+    public function eat($n) {}
+}`,
+			want: []parser.StepDefFound{
+				{Pattern: `^I have (\d+) cukes$`, FunctionName: "iHave", Line: 6, EndLine: 6},
+				{Pattern: "I eat :n", FunctionName: "eat", Line: 8, EndLine: 9},
+			},
+		},
+		{
+			name: "cucumber-jvm java",
+			lang: parser.LangJava,
+			content: `public class Steps {
+    @Given("^I have (\\d+) cukes$")
+    public void iHave(int n) {}
+
+    @When("I eat {int}")
+    @And("I am full")
+    void eat(int n) {}
+}`,
+			want: []parser.StepDefFound{
+				{Pattern: `^I have (\d+) cukes$`, FunctionName: "iHave", Line: 2, EndLine: 3},
+				{Pattern: "I eat {int}", FunctionName: "eat", Line: 5, EndLine: 7},
+				{Pattern: "I am full", FunctionName: "eat", Line: 5, EndLine: 7},
+			},
+		},
+		{
+			name: "cucumber-jvm kotlin",
+			lang: parser.LangKotlin,
+			content: `class Steps {
+    @Given("I have {int} cukes")
+    fun iHave(n: Int) {}
+}`,
+			want: []parser.StepDefFound{
+				{Pattern: "I have {int} cukes", FunctionName: "iHave", Line: 2, EndLine: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.ParseStepDefinitions([]byte(tt.content), tt.lang)
+			if err != nil {
+				t.Fatalf("ParseStepDefinitions failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
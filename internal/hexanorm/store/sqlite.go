@@ -117,6 +117,18 @@ func (s *Store) SaveEdge(edge *domain.Edge) error {
 	return err
 }
 
+// DeleteEdge removes an edge from the database.
+func (s *Store) DeleteEdge(edge *domain.Edge) error {
+	_, err := s.db.Exec("DELETE FROM edges WHERE source_id = ? AND target_id = ? AND type = ?", edge.SourceID, edge.TargetID, edge.Type)
+	return err
+}
+
+// DeleteEdgesFrom removes all edges of the given type leaving a node from the database.
+func (s *Store) DeleteEdgesFrom(sourceID string, edgeType domain.EdgeType) error {
+	_, err := s.db.Exec("DELETE FROM edges WHERE source_id = ? AND type = ?", sourceID, edgeType)
+	return err
+}
+
 // LoadAll retrieves all nodes and edges from the database.
 // It returns a slice of Nodes and a slice of Edges, or an error if the query fails.
 func (s *Store) LoadAll() ([]*domain.Node, []*domain.Edge, error) {
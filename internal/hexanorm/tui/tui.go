@@ -57,7 +57,7 @@ type Model struct {
 
 func NewModel(g *graph.Graph, a *analysis.Analyzer) Model {
 	// Initialize lists for each layer
-	layers := []string{"Domain", "Application", "Infrastructure", "Interface"}
+	layers := []string{"Domain", "Application", "Infrastructure", "Interface", "External"}
 	lists := make([]list.Model, len(layers))
 
 	nodes := g.GetAllNodes()
@@ -65,16 +65,21 @@ func NewModel(g *graph.Graph, a *analysis.Analyzer) Model {
 	// Group nodes
 	grouped := make(map[string][]list.Item)
 	for _, n := range nodes {
+		if n.Kind == domain.NodeKindSymbol {
+			continue // Symbols are listed in the details of their file
+		}
 		layer := "Other"
 		if l, ok := n.Metadata["layer"].(string); ok {
 			layer = toTitle(l)
 		} else if n.Kind == domain.NodeKindRequirement {
 			layer = "Domain" // Put reqs in Domain for now
+		} else if n.Kind == domain.NodeKindExternal {
+			layer = "External"
 		}
 
 		// Normalize layer names to match our columns
 		switch layer {
-		case "Domain", "Application", "Infrastructure", "Interface":
+		case "Domain", "Application", "Infrastructure", "Interface", "External":
 			// ok
 		default:
 			// maybe put in Interface or a separate Misc?
@@ -88,7 +88,7 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 		w.analyzeFile(event.Name)
 	} else if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
 		// Remove from graph
-		w.graph.RemoveNode(event.Name)
+		w.analyzer.RemoveFile(event.Name)
 		// If it was a directory, fsnotify usually removes the watch automatically, but we assume file-based graph for now.
 	}
 }
@@ -102,6 +102,7 @@ func (w *Watcher) analyzeFile(path string) {
 	if err := w.analyzer.AnalyzeFile(path, content); err != nil {
 		log.Printf("Failed to analyze file %s: %v", path, err)
 	} else {
+		w.analyzer.IndexCallGraphOf(path)
 		log.Printf("Analyzed %s", path)
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/analysis"
@@ -32,6 +33,12 @@ func main() {
 		case "tui":
 			handleTUI(os.Args[2:])
 			return
+		case "baseline":
+			handleBaseline(os.Args[2:])
+			return
+		case "check":
+			handleCheck(os.Args[2:])
+			return
 		}
 	}
 
@@ -58,7 +65,7 @@ func main() {
 
 func handleExport(args []string) {
 	exportCmd := flag.NewFlagSet("export", flag.ExitOnError)
-	format := exportCmd.String("format", "json", "Export format (json, excalidraw)")
+	format := exportCmd.String("format", "json", "Export format (json, excalidraw, metrics, metrics-csv)")
 	out := exportCmd.String("out", "architecture.json", "Output file path")
 
 	exportCmd.Parse(args)
@@ -81,15 +88,21 @@ func handleExport(args []string) {
 		os.Exit(1)
 	}
 	g := graph.NewGraph(st)
-	an := analysis.NewAnalyzer(g)
+	an := analysis.NewAnalyzer(g, cfg)
+	an.RootDir = absRoot
 
 	scanDirectory(absRoot, an)
 
 	fmt.Printf("Exporting architecture from %s to %s (format: %s)...\n", rootDir, *out, *format)
 
-	if *format == "excalidraw" {
+	switch *format {
+	case "excalidraw":
 		err = export.ExportExcalidraw(g, *out)
-	} else {
+	case "metrics":
+		err = export.ExportMetrics(an.CalculateMetrics(), *out)
+	case "metrics-csv":
+		err = export.ExportMetricsCSV(an.CalculateMetrics(), *out)
+	default:
 		// Default JSON placeholder
 		fmt.Println("JSON export not implemented yet")
 	}
@@ -119,7 +132,11 @@ func handleTUI(args []string) {
 		os.Exit(1)
 	}
 	g := graph.NewGraph(st)
-	an := analysis.NewAnalyzer(g)
+	an := analysis.NewAnalyzer(g, cfg)
+	an.RootDir = absRoot
+	if an.Baseline, err = analysis.LoadBaseline(filepath.Join(absRoot, cfg.BaselineFile)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load baseline: %v\n", err)
+	}
 
 	scanDirectory(absRoot, an)
 
@@ -131,7 +148,106 @@ func handleTUI(args []string) {
 	}
 }
 
+// handleBaseline writes the baseline file accepting the current violations, keeping the dates and
+// justification of the entries that were already accepted.
+func handleBaseline(args []string) {
+	baselineCmd := flag.NewFlagSet("baseline", flag.ExitOnError)
+	justification := baselineCmd.String("justification", "", "Why the new entries are accepted, e.g. a ticket")
+	expires := baselineCmd.String("expires", "", "Date the new entries expire (YYYY-MM-DD)")
+
+	baselineCmd.Parse(args)
+
+	if *expires != "" {
+		if _, err := time.Parse("2006-01-02", *expires); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid expiry date %q: %v\n", *expires, err)
+			os.Exit(1)
+		}
+	}
+
+	rootDir := "."
+	if baselineCmd.NArg() > 0 {
+		rootDir = baselineCmd.Arg(0)
+	}
+	absRoot, _ := filepath.Abs(rootDir)
+
+	cfg, err := config.LoadConfig(absRoot)
+	if err != nil {
+		cfg = &config.DefaultConfig
+	}
+	path := filepath.Join(absRoot, cfg.BaselineFile)
+	previous, err := analysis.LoadBaseline(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load baseline: %v\n", err)
+		os.Exit(1)
+	}
+
+	// The baseline only needs the current violations, not a persisted graph
+	an := analysis.NewAnalyzer(graph.NewGraph(nil), cfg)
+	an.RootDir = absRoot
+	an.Baseline = previous
+	scanDirectory(absRoot, an)
+
+	violations := an.FindViolations()
+	accepted := 0
+	for _, v := range violations {
+		if v.Baselined {
+			accepted++
+		}
+	}
+	b := an.NewBaseline(violations, previous, *expires, *justification, time.Now())
+	if err := b.Save(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write baseline: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Baselined %d violations (%d new) in %s\n", len(b.Entries), len(violations)-accepted, path)
+}
+
+// handleCheck reports the violations the baseline does not accept. It exits with status 1 if there are any,
+// so that CI only fails on new violations, and with status 2 if the check cannot run.
+func handleCheck(args []string) {
+	checkCmd := flag.NewFlagSet("check", flag.ExitOnError)
+	checkCmd.Parse(args)
+
+	rootDir := "."
+	if checkCmd.NArg() > 0 {
+		rootDir = checkCmd.Arg(0)
+	}
+	absRoot, _ := filepath.Abs(rootDir)
+
+	cfg, err := config.LoadConfig(absRoot)
+	if err != nil {
+		cfg = &config.DefaultConfig
+	}
+	baseline, err := analysis.LoadBaseline(filepath.Join(absRoot, cfg.BaselineFile))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load baseline: %v\n", err)
+		os.Exit(2)
+	}
+
+	// The check only needs the current violations, not a persisted graph
+	an := analysis.NewAnalyzer(graph.NewGraph(nil), cfg)
+	an.RootDir = absRoot
+	an.Baseline = baseline
+	scanDirectory(absRoot, an)
+
+	violations := an.FindViolations()
+	fresh := 0
+	for _, v := range violations {
+		if v.Baselined {
+			continue
+		}
+		fresh++
+		file, _ := filepath.Rel(absRoot, v.File)
+		fmt.Printf("%s:%d: %s %s: %s\n", filepath.ToSlash(file), v.Line, v.Severity, v.Kind, v.Message)
+	}
+	fmt.Printf("%d new violations (%d baselined)\n", fresh, len(violations)-fresh)
+	if fresh > 0 {
+		os.Exit(1)
+	}
+}
+
 func scanDirectory(root string, an *analysis.Analyzer) {
+	var manifests, files []string
 	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -142,11 +258,24 @@ func scanDirectory(root string, an *analysis.Analyzer) {
 			}
 			return nil
 		}
-
-		content, err := os.ReadFile(path)
-		if err == nil {
-			an.AnalyzeFile(path, content)
+		if analysis.IsManifest(path) {
+			manifests = append(manifests, path)
+		} else {
+			files = append(files, path)
 		}
 		return nil
 	})
+
+	// Manifests first, so that imports resolve regardless of the walk order
+	for _, path := range append(manifests, files...) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if err := an.AnalyzeFile(path, content); err != nil {
+			log.Printf("Failed to analyze file %s: %v", path, err)
+		}
+	}
+	// Calls resolve against the symbols of every file, so once all are analyzed
+	an.IndexCallGraph()
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/analysis"
+	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/config"
 	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/domain"
 	"github.com/modelcontextprotocol/go-sdk/examples/server/hexanorm/internal/hexanorm/graph"
 )
@@ -18,27 +19,12 @@ import (
 // 3. BDD traceability links (Scenario -> StepDefinition) are established.
 func TestHexanorm(t *testing.T) {
 	g := graph.NewGraph(nil) // Use in-memory for tests
-	an := analysis.NewAnalyzer(g)
+	an := analysis.NewAnalyzer(g, nil)
 
-	cwd, _ := os.Getwd()
-	testRoot := filepath.Join(cwd, "testdata")
-
-	// Manually scan
-	err := filepath.Walk(testRoot, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() {
-			content, _ := os.ReadFile(path)
-			return an.AnalyzeFile(path, content)
-		}
-		return nil
-	})
-	if err != nil {
-		t.Fatalf("Walk failed: %v", err)
-	}
+	scanTestdata(t, an)
 
 	an.IndexStepDefinitions()
+	an.IndexCallGraph()
 
 	// Check Violation
 	violations := an.FindViolations()
@@ -94,3 +80,66 @@ func TestHexanorm(t *testing.T) {
 		t.Error("Expected Scenario to EXECUTE StepDefinition")
 	}
 }
+
+// TestLayerRulesFromConfig verifies that violations are computed from the configured dependency matrix.
+func TestLayerRulesFromConfig(t *testing.T) {
+	cfg := config.DefaultConfig
+	cfg.LayerRules = []config.LayerRule{
+		{Layer: "domain", MayImport: []string{"infrastructure"}, Severity: domain.SeverityCritical},
+	}
+
+	an := analysis.NewAnalyzer(graph.NewGraph(nil), &cfg)
+	scanTestdata(t, an)
+
+	for _, v := range an.FindViolations() {
+		if v.Kind == domain.ViolationKindArchLayer {
+			t.Errorf("Unexpected architecture violation: %s", v.Message)
+		}
+	}
+
+	cfg.LayerRules = []config.LayerRule{
+		{Layer: "domain", MayImport: []string{"application"}, Severity: domain.SeverityWarning},
+	}
+	found := false
+	for _, v := range an.FindViolations() {
+		if v.Kind == domain.ViolationKindArchLayer && strings.Contains(v.Message, "Broken.ts") {
+			found = v.Severity == domain.SeverityWarning
+		}
+	}
+	if !found {
+		t.Error("Expected WARNING architecture violation in Broken.ts")
+	}
+}
+
+// scanTestdata analyzes every file under the testdata directory.
+func scanTestdata(t *testing.T, an *analysis.Analyzer) {
+	t.Helper()
+
+	cwd, _ := os.Getwd()
+	testRoot := filepath.Join(cwd, "testdata")
+
+	// Manually scan, manifests first
+	var manifests, files []string
+	err := filepath.Walk(testRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			if analysis.IsManifest(path) {
+				manifests = append(manifests, path)
+			} else {
+				files = append(files, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	for _, path := range append(manifests, files...) {
+		content, _ := os.ReadFile(path)
+		if err := an.AnalyzeFile(path, content); err != nil {
+			t.Fatalf("AnalyzeFile(%s) failed: %v", path, err)
+		}
+	}
+}